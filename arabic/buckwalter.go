@@ -0,0 +1,87 @@
+package arabic
+
+import "strings"
+
+// buckwalterMap is a map that maps the characters of the extended
+// Buckwalter transliteration, as used by the Quranic Arabic Corpus,
+// to their arabic script notation.
+var buckwalterMap map[rune]rune = map[rune]rune{
+	'\'': 'ء', // Hamza
+	'|':  'آ', // Alif with madda above
+	'>':  'أ', // Alif with hamza above
+	'&':  'ؤ', // Waw with hamza above
+	'<':  'إ', // Alif with hamza below
+	'}':  'ئ', // Ya with hamza above
+	'A':  'ا', // Alif
+	'b':  'ب', // Ba
+	'p':  'ة', // Ta marbuta
+	't':  'ت', // Ta
+	'v':  'ث', // Tha
+	'j':  'ج', // Jim
+	'H':  'ح', // Ha
+	'x':  'خ', // Kha
+	'd':  'د', // Dal
+	'*':  'ذ', // Dhal
+	'r':  'ر', // Ra
+	'z':  'ز', // Zay
+	's':  'س', // Sin
+	'$':  'ش', // Shin
+	'S':  'ص', // Sad
+	'D':  'ض', // Dad
+	'T':  'ط', // Ta
+	'Z':  'ظ', // Za
+	'E':  'ع', // Ayn
+	'g':  'غ', // Ghayn
+	'_':  'ـ', // Tatweel
+	'f':  'ف', // Fa
+	'q':  'ق', // Qaf
+	'k':  'ك', // Kaf
+	'l':  'ل', // Lam
+	'm':  'م', // Mim
+	'n':  'ن', // Nun
+	'h':  'ه', // Ha
+	'w':  'و', // Waw
+	'Y':  'ى', // Alif maksura
+	'y':  'ي', // Ya
+	'F':  'ً', // Fathatan
+	'N':  'ٌ', // Dammatan
+	'K':  'ٍ', // Kasratan
+	'a':  'َ', // Fatha
+	'u':  'ُ', // Damma
+	'i':  'ِ', // Kasra
+	'~':  'ّ', // Shadda
+	'o':  'ْ', // Sukun
+	'^':  'ٓ', // Maddah above
+	'#':  'ٔ', // Hamza above
+	'`':  'ٰ', // Superscript alif
+	'{':  'ٱ', // Alif wasla
+	':':  'ۜ', // Small high sin
+	'@':  '۟', // Small high rounded zero
+	'"':  '۠', // Small high upright rectangular zero
+	'[':  'ۢ', // Small high mim
+	';':  'ۣ', // Small low sin
+	',':  'ۥ', // Small waw
+	'.':  'ۦ', // Small ya
+	'!':  'ۨ', // Small high nun
+	'-':  '۪', // Empty centre low stop
+	'+':  '۫', // Empty centre high stop
+	'%':  '۬', // Rounded high stop with filled centre
+	']':  'ۭ', // Small low mim
+}
+
+// FromBuckwalter converts a string in the extended Buckwalter
+// transliteration to its arabic script notation. The characters
+// that are not part of the transliteration are kept as is.
+func FromBuckwalter(s string) string {
+	var b strings.Builder
+
+	for _, c := range s {
+		if r, ok := buckwalterMap[c]; ok {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune(c)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,57 @@
+package arabic
+
+import "strings"
+
+// normalizeMap is a map that maps the letters having several
+// written forms to the form they are normalized to.
+var normalizeMap map[rune]rune = map[rune]rune{
+	'أ': 'ا', // Alif with hamza above
+	'إ': 'ا', // Alif with hamza below
+	'آ': 'ا', // Alif with madda above
+	'ٱ': 'ا', // Alif wasla
+	'ٲ': 'ا', // Alif with wavy hamza above
+	'ٳ': 'ا', // Alif with wavy hamza below
+	'ؤ': 'و', // Waw with hamza above
+	'ئ': 'ي', // Ya with hamza above
+	'ى': 'ي', // Alif maksura
+	'ی': 'ي', // Farsi ya
+	'ة': 'ه', // Ta marbuta
+}
+
+// Normalize normalizes arabic text for searching, regardless
+// of diacritics and orthography. It strips the harakat, the
+// quranic annotation marks and the tatweel, and unifies the
+// forms of alif and hamza, ta marbuta with ha and alif maksura
+// with ya. The other characters are kept as is.
+func Normalize(s string) string {
+	var b strings.Builder
+
+	for _, c := range s {
+		if IsMark(c) || c == 'ـ' {
+			continue
+		}
+
+		if r, ok := normalizeMap[c]; ok {
+			c = r
+		}
+
+		b.WriteRune(c)
+	}
+
+	return b.String()
+}
+
+// IsMark returns true if a rune is an arabic diacritic or quranic annotation mark.
+func IsMark(r rune) bool {
+	switch {
+	case r >= 'ؐ' && r <= 'ؚ': // honorifics and small high marks
+		return true
+	case r >= 'ً' && r <= 'ٟ': // harakat
+		return true
+	case r == 'ٰ': // superscript alif
+		return true
+	case r >= 'ۖ' && r <= 'ۭ': // quranic annotation signs
+		return true
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package arabic
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", ""},
+		{"بِسْمِ ٱللَّهِ", "بسم الله"},
+		{"ٱلرَّحْمَٰنِ", "الرحمن"},
+		{"كِتَٰبٌ", "كتب"},
+		{"أَحَدٌ", "احد"},
+		{"إِلَٰه", "اله"},
+		{"آمَنُوا", "امنوا"},
+		{"مُؤْمِن", "مومن"},
+		{"شَىْءٍ", "شيء"},
+		{"سَآئِل", "سايل"},
+		{"رَحْمَة", "رحمه"},
+		{"هُدًى", "هدي"},
+		{"فارسی", "فارسي"},
+		{"الـــله", "الله"},
+		{"ذَٰلِكَ ٱلْكِتَٰبُ لَا رَيْبَ ۛ فِيهِ ۛ", "ذلك الكتب لا ريب  فيه "},
+		{"Allah 123", "Allah 123"},
+		{"قُلْ: هُوَ!", "قل: هو!"},
+	}
+
+	for _, tt := range tests {
+		if got := Normalize(tt.in); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeIdempotent(t *testing.T) {
+	for _, s := range []string{"بِسْمِ ٱللَّهِ ٱلرَّحْمَٰنِ ٱلرَّحِيمِ", "إِيَّاكَ نَعْبُدُ", "مُؤْمِنَةٌ"} {
+		once := Normalize(s)
+		if twice := Normalize(once); twice != once {
+			t.Errorf("Normalize(Normalize(%q)) = %q, want %q", s, twice, once)
+		}
+	}
+}
+
+func TestIsMark(t *testing.T) {
+	tests := []struct {
+		r    rune
+		want bool
+	}{
+		{'َ', true},  // fatha
+		{'ّ', true},  // shadda
+		{'ْ', true},  // sukun
+		{'ٰ', true},  // superscript alif
+		{'ۛ', true},  // small high three dots
+		{'ۥ', true},  // small waw
+		{'ؐ', true},  // sign sallallahou alayhe wassallam
+		{'ا', false}, // alif
+		{'ء', false}, // hamza
+		{'ـ', false}, // tatweel
+		{'a', false},
+		{' ', false},
+	}
+
+	for _, tt := range tests {
+		if got := IsMark(tt.r); got != tt.want {
+			t.Errorf("IsMark(%q) = %v, want %v", tt.r, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package arabic
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Root is the root of arabic words, the letters
+// from which they are derived, written without spaces.
+type Root string
+
+// Lemma is the dictionary form of an arabic word.
+type Lemma string
+
+// hamzas maps the letters carrying a hamza to the hamza,
+// as written in the roots of the Quranic Arabic Corpus.
+var hamzas = map[rune]rune{
+	'أ': 'ء',
+	'إ': 'ء',
+	'آ': 'ء',
+	'ؤ': 'ء',
+	'ئ': 'ء',
+}
+
+// ParseRoot parses the letters of a root, in arabic script or in the
+// Buckwalter transliteration, possibly separated by spaces. The letters
+// carrying a hamza are read as the hamza.
+func ParseRoot(s string) (Root, error) {
+	var letters []rune
+
+	for _, c := range FromBuckwalter(strings.Join(strings.Fields(s), "")) {
+		if !unicode.Is(unicode.Arabic, c) || !unicode.IsLetter(c) {
+			return "", fmt.Errorf("invalid root %q", s)
+		}
+		if h, ok := hamzas[c]; ok {
+			c = h
+		}
+		letters = append(letters, c)
+	}
+
+	if len(letters) < 2 || len(letters) > 5 {
+		return "", fmt.Errorf("invalid root %q, must have between 2 and 5 letters", s)
+	}
+
+	return Root(letters), nil
+}
+
+// String returns the letters of the root separated by spaces.
+func (r Root) String() string {
+	letters := make([]string, 0, len(r))
+	for _, c := range r {
+		letters = append(letters, string(c))
+	}
+	return strings.Join(letters, " ")
+}
+
+// String returns the lemma.
+func (l Lemma) String() string {
+	return string(l)
+}
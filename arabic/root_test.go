@@ -0,0 +1,58 @@
+package arabic
+
+import "testing"
+
+func TestParseRoot(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Root
+		err  bool
+	}{
+		{"كتب", "كتب", false},
+		{"ك ت ب", "كتب", false},
+		{" ك  ت\tب ", "كتب", false},
+		{"ktb", "كتب", false},
+		{"k t b", "كتب", false},
+		{"Elm", "علم", false},
+		{"'mn", "ءمن", false},
+		{"أمن", "ءمن", false},
+		{">mn", "ءمن", false},
+		{"إله", "ءله", false},
+		{"<lh", "ءله", false},
+		{"آمن", "ءمن", false},
+		{"سأل", "سءل", false},
+		{"بؤس", "بءس", false},
+		{"&", "", true},
+		{"بئر", "بءر", false},
+		{"b}r", "بءر", false},
+		{"رب", "رب", false},
+		{"زلزل", "زلزل", false},
+		{"", "", true},
+		{"ك", "", true},
+		{"ktbktb", "", true},
+		{"كَتَبَ", "", true},
+		{"k1b", "", true},
+		{"abc", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseRoot(tt.in)
+
+		if tt.err {
+			if err == nil {
+				t.Errorf("ParseRoot(%q) = %q, want an error", tt.in, got)
+			}
+			continue
+		}
+
+		if err != nil || got != tt.want {
+			t.Errorf("ParseRoot(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestRootString(t *testing.T) {
+	if got := Root("كتب").String(); got != "ك ت ب" {
+		t.Errorf("String() = %q, want %q", got, "ك ت ب")
+	}
+}
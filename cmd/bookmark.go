@@ -0,0 +1,274 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/urfave/cli/v2"
+	"github.com/vanillaiice/quran-cli/db"
+	"github.com/vanillaiice/quran-cli/tui"
+	"github.com/vanillaiice/quran-cli/user"
+)
+
+// bookmarkCmd is the bookmark command.
+// It manages the bookmarked verses.
+var bookmarkCmd = &cli.Command{
+	Name:    "bookmark",
+	Aliases: []string{"b"},
+	Usage:   "manage bookmarks",
+	Subcommands: []*cli.Command{
+		{
+			Name:      "add",
+			Aliases:   []string{"a"},
+			Usage:     "bookmark a verse",
+			ArgsUsage: "NAME REFERENCE",
+			Flags:     []cli.Flag{dataPathFlag()},
+			Action: func(ctx *cli.Context) (err error) {
+				if ctx.NArg() != 2 {
+					return fmt.Errorf("please specify a bookmark name and a verse")
+				}
+
+				name, reference := ctx.Args().Get(0), ctx.Args().Get(1)
+
+				dataPath, err := getDataPath(ctx.String("data-path"))
+				if err != nil {
+					return
+				}
+
+				surahId, verseId, err := resolveVerse(dataPath, reference)
+				if err != nil {
+					return
+				}
+
+				u, err := openUserDb(dataPath)
+				if err != nil {
+					return
+				}
+				defer u.Close()
+
+				if err = u.AddBookmark(name, surahId, verseId); err != nil {
+					return
+				}
+
+				fmt.Printf("bookmarked %d:%d as %q\n", surahId, verseId, name)
+
+				return
+			},
+		},
+		{
+			Name:    "list",
+			Aliases: []string{"l"},
+			Usage:   "list bookmarks",
+			Flags:   []cli.Flag{dataPathFlag()},
+			Action: func(ctx *cli.Context) (err error) {
+				dataPath, err := getDataPath(ctx.String("data-path"))
+				if err != nil {
+					return
+				}
+
+				u, err := openUserDb(dataPath)
+				if err != nil {
+					return
+				}
+				defer u.Close()
+
+				bookmarks, err := u.GetBookmarks()
+				if err != nil {
+					return
+				}
+
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+				for _, b := range bookmarks {
+					fmt.Fprintf(w, "%s\t%d:%d\t%s\n", b.Name, b.SurahId, b.VerseId, b.CreatedAt.Format("2006-01-02 15:04"))
+				}
+
+				return w.Flush()
+			},
+		},
+		{
+			Name:      "remove",
+			Aliases:   []string{"rm"},
+			Usage:     "remove a bookmark",
+			ArgsUsage: "NAME",
+			Flags:     []cli.Flag{dataPathFlag()},
+			Action: func(ctx *cli.Context) (err error) {
+				if ctx.NArg() != 1 {
+					return fmt.Errorf("please specify a bookmark name")
+				}
+
+				dataPath, err := getDataPath(ctx.String("data-path"))
+				if err != nil {
+					return
+				}
+
+				u, err := openUserDb(dataPath)
+				if err != nil {
+					return
+				}
+				defer u.Close()
+
+				if err = u.RemoveBookmark(ctx.Args().First()); errors.Is(err, user.ErrNotFound) {
+					return fmt.Errorf("bookmark %q not found", ctx.Args().First())
+				}
+
+				return
+			},
+		},
+		{
+			Name:      "open",
+			Aliases:   []string{"o"},
+			Usage:     "read the surah of a bookmark, starting at its verse",
+			ArgsUsage: "NAME",
+			Flags: []cli.Flag{
+				dataPathFlag(),
+				&cli.StringFlag{
+					Name:    "style",
+					Aliases: []string{"t"},
+					Usage:   "use terminal ui style `STYLE` (tview, list)",
+					Value:   "list",
+				},
+				&cli.StringFlag{
+					Name:    "language",
+					Aliases: []string{"l"},
+					Usage:   "read in `LANGUAGE`",
+					Value:   "en",
+				},
+				&cli.StringFlag{
+					Name:    "mode",
+					Aliases: []string{"m"},
+					Usage:   "reading mode `MODE` (arabic, translation, both, interlinear)",
+					Value:   "both",
+				},
+				&cli.StringFlag{
+					Name:  "tafsir",
+					Usage: "show the commentary of tafsir `NAME` in the tview style",
+				},
+			},
+			Action: func(ctx *cli.Context) (err error) {
+				if ctx.NArg() != 1 {
+					return fmt.Errorf("please specify a bookmark name")
+				}
+
+				lang := ctx.String("language")
+
+				mode, err := parseMode(ctx.String("mode"))
+				if err != nil {
+					return
+				}
+
+				dataPath, err := getDataPath(ctx.String("data-path"))
+				if err != nil {
+					return
+				}
+
+				u, err := openUserDb(dataPath)
+				if err != nil {
+					return
+				}
+				defer u.Close()
+
+				b, err := getBookmark(u, ctx.Args().First())
+				if err != nil {
+					return
+				}
+
+				d, err := openDb(dataPath)
+				if err != nil {
+					return
+				}
+				defer d.Close()
+
+				ok, err := d.HasLanguage(lang)
+				if err != nil {
+					return
+				}
+
+				if !ok {
+					return fmt.Errorf("data for language %s not found, initialize it with the init command", lang)
+				}
+
+				surah, err := d.GetSurahById(b.SurahId, lang)
+				if err != nil {
+					return
+				}
+
+				surahs := []*db.Surah{surah}
+
+				sources, err := loadSources(dataPath)
+				if err != nil {
+					return
+				}
+
+				cfg, err := readConfig(d, u, sources, lang, mode, ctx.String("tafsir"))
+				if err != nil {
+					return
+				}
+				cfg.Start = tui.Index(surahs, b.SurahId, b.VerseId)
+
+				if mode == tui.Interlinear {
+					if err = loadWords(d, surahs); err != nil {
+						return
+					}
+				}
+
+				return runTui(ctx.String("style"), surahs, cfg)
+			},
+		},
+	},
+}
+
+// getBookmark returns the bookmark with the name name.
+func getBookmark(u *user.Conn, name string) (*user.Bookmark, error) {
+	b, err := u.GetBookmark(name)
+	if errors.Is(err, user.ErrNotFound) {
+		return nil, fmt.Errorf("bookmark %q not found", name)
+	}
+	return b, err
+}
+
+// bookmarkFunc returns a function bookmarking verses from
+// the terminal ui, naming the bookmarks after the verses.
+func bookmarkFunc(u *user.Conn) func(surahId, verseId int) (string, error) {
+	return func(surahId, verseId int) (string, error) {
+		name := fmt.Sprintf("%d:%d", surahId, verseId)
+
+		if err := u.AddBookmark(name, surahId, verseId); err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("bookmarked verse %s", name), nil
+	}
+}
+
+// resolveVerse returns the surah and verse numbers
+// of the single verse referenced by reference.
+func resolveVerse(dataPath, reference string) (surahId, verseId int, err error) {
+	d, err := openDb(dataPath)
+	if err != nil {
+		return
+	}
+	defer d.Close()
+
+	ok, err := d.HasLanguage(db.Arabic)
+	if err != nil {
+		return
+	}
+
+	if !ok {
+		return 0, 0, fmt.Errorf("no data found, initialize it with the init command")
+	}
+
+	surahs, err := readRefs(d, reference, db.Arabic, false)
+	if err != nil {
+		return
+	}
+
+	if len(surahs) != 1 || len(surahs[0].Verses) != 1 {
+		return 0, 0, fmt.Errorf("reference %q is not a single verse", reference)
+	}
+
+	return surahs[0].Id, surahs[0].Verses[0].Id, nil
+}
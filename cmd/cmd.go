@@ -1,17 +1,26 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path"
+	"path/filepath"
+	"strings"
 
 	"github.com/charmbracelet/log"
 	"github.com/urfave/cli/v2"
+	"github.com/vanillaiice/quran-cli/db"
+	"github.com/vanillaiice/quran-cli/user"
 	"github.com/vanillaiice/quran-cli/version"
 )
 
 const (
-	perm    = 0644         // file mode
-	dataDir = ".quran-cli" // data directory
+	perm       = 0644         // file mode
+	dataDir    = ".quran-cli" // data directory
+	dbName     = "quran.db"   // database file name
+	userDbName = "user.db"    // user data database file name
 )
 
 // Exec executes the app.
@@ -32,6 +41,15 @@ func Exec() {
 		Commands: []*cli.Command{
 			initCmd,
 			readCmd,
+			searchCmd,
+			bookmarkCmd,
+			noteCmd,
+			verifyCmd,
+			importCmd,
+			languagesCmd,
+			removeCmd,
+			updateCmd,
+			tafsirCmd,
 		},
 	}
 
@@ -62,3 +80,146 @@ func Exec() {
 		log.Fatal(err)
 	}
 }
+
+// getDataPath returns the data path, defaulting
+// to the data directory in the user's home.
+func getDataPath(dataPath string) (string, error) {
+	if dataPath != "" {
+		return dataPath, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return path.Join(home, dataDir), nil
+}
+
+// makeDataPath creates the data path if it does not exist.
+func makeDataPath(dataPath string) (err error) {
+	if _, err = os.Stat(dataPath); errors.Is(err, os.ErrNotExist) {
+		if err = os.MkdirAll(dataPath, os.ModePerm); err != nil {
+			return
+		}
+		log.Debugf("created data directory %q", dataPath)
+	}
+	return
+}
+
+// openDb opens the database in the data path, creating the data path
+// if needed, and imports the databases left by former versions.
+func openDb(dataPath string) (d *db.Conn, err error) {
+	if err = makeDataPath(dataPath); err != nil {
+		return
+	}
+
+	d, err = db.New(path.Join(dataPath, dbName))
+	if errors.Is(err, db.ErrLegacySchema) {
+		return nil, fmt.Errorf("%s: %w, rename it quran_<lang>.db to import it", path.Join(dataPath, dbName), err)
+	} else if err != nil {
+		return
+	}
+
+	if err = importLegacy(d, dataPath); err != nil {
+		d.Close()
+		return nil, err
+	}
+
+	return
+}
+
+// replaceDb applies update to a copy of the database of dataPath, then
+// renames the copy over the database, which is left unchanged if update
+// or the copy fail.
+func replaceDb(dataPath string, update func(d *db.Conn) error) (err error) {
+	file := path.Join(dataPath, dbName)
+	tmp := file + ".tmp"
+
+	if err = os.Remove(tmp); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+
+	d, err := openDb(dataPath)
+	if err != nil {
+		return
+	}
+
+	err = d.CopyTo(tmp)
+	d.Close()
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			os.Remove(tmp)
+		}
+	}()
+
+	t, err := db.New(tmp)
+	if err != nil {
+		return
+	}
+
+	if err = update(t); err != nil {
+		t.Close()
+		return
+	}
+
+	if err = t.Close(); err != nil {
+		return
+	}
+
+	return os.Rename(tmp, file)
+}
+
+// importLegacy imports the per language databases of former versions,
+// named quran_<lang>.db, and renames them once imported.
+func importLegacy(d *db.Conn, dataPath string) (err error) {
+	files, err := filepath.Glob(path.Join(dataPath, "quran_*.db"))
+	if err != nil {
+		return
+	}
+
+	for _, file := range files {
+		lang := strings.TrimSuffix(strings.TrimPrefix(path.Base(file), "quran_"), ".db")
+
+		ok, err := d.HasLanguage(lang)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			if err = d.ImportLegacy(file, lang); err != nil {
+				return fmt.Errorf("importing %q: %w", file, err)
+			}
+			log.Infof("imported database %q for language %s", file, lang)
+		}
+
+		if err = os.Rename(file, file+".imported"); err != nil {
+			return err
+		}
+	}
+
+	return
+}
+
+// openUserDb opens the user data database in the data path.
+func openUserDb(dataPath string) (*user.Conn, error) {
+	if err := makeDataPath(dataPath); err != nil {
+		return nil, err
+	}
+
+	return user.New(path.Join(dataPath, userDbName))
+}
+
+// dataPathFlag returns the flag setting the data path.
+func dataPathFlag() cli.Flag {
+	return &cli.PathFlag{
+		Name:    "data-path",
+		Aliases: []string{"p"},
+		Usage:   "data path `PATH`",
+		Value:   "",
+	}
+}
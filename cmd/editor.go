@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// editText opens the user's editor on a temporary file holding
+// text, and returns the content of the file once the editor exits.
+func editText(text string) (string, error) {
+	f, err := os.CreateTemp("", "quran-cli-note-*.txt")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err = f.WriteString(text); err != nil {
+		f.Close()
+		return "", err
+	}
+
+	if err = f.Close(); err != nil {
+		return "", err
+	}
+
+	args := editorCommand()
+
+	cmd := exec.Command(args[0], append(args[1:], f.Name())...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+
+	if err = cmd.Run(); err != nil {
+		return "", err
+	}
+
+	b, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(b)), nil
+}
+
+// editorCommand returns the command running the user's editor, set in the
+// VISUAL or EDITOR variables possibly with arguments, as in "code --wait".
+// It defaults to vi if both are empty or blank.
+func editorCommand() []string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if args := strings.Fields(os.Getenv(name)); len(args) > 0 {
+			return args
+		}
+	}
+
+	return []string{"vi"}
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEditorCommand(t *testing.T) {
+	tests := []struct {
+		name   string
+		visual string
+		editor string
+		want   []string
+	}{
+		{"visual", "nano", "emacs", []string{"nano"}},
+		{"editor", "", "emacs", []string{"emacs"}},
+		{"arguments", "code --wait", "", []string{"code", "--wait"}},
+		{"blank visual", "  \t", "emacs -nw", []string{"emacs", "-nw"}},
+		{"blank", " ", " ", []string{"vi"}},
+		{"unset", "", "", []string{"vi"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("VISUAL", tt.visual)
+			t.Setenv("EDITOR", tt.editor)
+
+			if got := editorCommand(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("editorCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
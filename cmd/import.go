@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/urfave/cli/v2"
+	"github.com/vanillaiice/quran-cli/source"
+)
+
+// importCmd is the import command.
+// It imports additional data from local files.
+var importCmd = &cli.Command{
+	Name:    "import",
+	Aliases: []string{"im"},
+	Usage:   "import additional data from files",
+	Subcommands: []*cli.Command{
+		{
+			Name:      "words",
+			Aliases:   []string{"w"},
+			Usage:     "import word-by-word data, replacing the existing one",
+			ArgsUsage: "FILE",
+			Description: "FILE holds one word per line, with tab separated location (surah:verse:position),\n" +
+				"arabic text, transliteration and gloss. Use - to read from the standard input.",
+			Flags: []cli.Flag{dataPathFlag()},
+			Action: func(ctx *cli.Context) (err error) {
+				if ctx.NArg() != 1 {
+					return fmt.Errorf("please specify a file")
+				}
+
+				dataPath, err := getDataPath(ctx.String("data-path"))
+				if err != nil {
+					return
+				}
+
+				r, err := openInput(ctx.Args().First())
+				if err != nil {
+					return
+				}
+				defer r.Close()
+
+				d, err := openDb(dataPath)
+				if err != nil {
+					return
+				}
+				defer d.Close()
+
+				if err = d.ImportWords(bufio.NewReader(r), progressFunc("importing words")); err != nil {
+					return
+				}
+
+				log.Info("imported word-by-word data")
+
+				return
+			},
+		},
+		{
+			Name:      "morphology",
+			Aliases:   []string{"mo"},
+			Usage:     "import morphological data, replacing the existing one",
+			ArgsUsage: "FILE",
+			Description: "FILE is in the format of the Quranic Arabic Corpus morphology, with one word segment per line.\n" +
+				"Use - to read from the standard input.",
+			Flags: []cli.Flag{dataPathFlag()},
+			Action: func(ctx *cli.Context) (err error) {
+				if ctx.NArg() != 1 {
+					return fmt.Errorf("please specify a file")
+				}
+
+				dataPath, err := getDataPath(ctx.String("data-path"))
+				if err != nil {
+					return
+				}
+
+				r, err := openInput(ctx.Args().First())
+				if err != nil {
+					return
+				}
+				defer r.Close()
+
+				d, err := openDb(dataPath)
+				if err != nil {
+					return
+				}
+				defer d.Close()
+
+				if err = d.ImportMorphology(bufio.NewReader(r), progressFunc("importing morphology")); err != nil {
+					return
+				}
+
+				log.Info("imported morphological data")
+
+				return
+			},
+		},
+		{
+			Name:      "translation",
+			Aliases:   []string{"tr"},
+			Usage:     "import a translation in a Tanzil format",
+			ArgsUsage: "FILE",
+			Description: "FILE is a Tanzil translation, in the text format (sura|aya|text) or the XML one.\n" +
+				"The format is guessed from the extension of FILE if not given. Use - to read from the standard input.",
+			Flags: []cli.Flag{
+				dataPathFlag(),
+				&cli.StringFlag{
+					Name:     "language",
+					Aliases:  []string{"l"},
+					Usage:    "store the translation under language `LANGUAGE`",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:  "format",
+					Usage: "format `FORMAT` of the file (text, xml)",
+				},
+				&cli.BoolFlag{
+					Name:    "force",
+					Aliases: []string{"f"},
+					Usage:   "replace existing translation if exists",
+				},
+			},
+			Action: func(ctx *cli.Context) (err error) {
+				if ctx.NArg() != 1 {
+					return fmt.Errorf("please specify a file")
+				}
+
+				lang, file := ctx.String("language"), ctx.Args().First()
+				if !source.ValidCode(lang) {
+					return fmt.Errorf("invalid language code: %q", lang)
+				}
+
+				format := ctx.String("format")
+				if format == "" {
+					format = "text"
+					if strings.EqualFold(filepath.Ext(file), ".xml") {
+						format = "xml"
+					}
+				}
+
+				dataPath, err := getDataPath(ctx.String("data-path"))
+				if err != nil {
+					return
+				}
+
+				d, err := openDb(dataPath)
+				if err != nil {
+					return
+				}
+				defer d.Close()
+
+				ok, err := d.HasLanguage(lang)
+				if err != nil {
+					return
+				}
+
+				if ok && !ctx.Bool("force") {
+					return fmt.Errorf("data already exists for language %s", lang)
+				}
+
+				r, err := openInput(file)
+				if err != nil {
+					return
+				}
+				defer r.Close()
+
+				progress := progressFunc(fmt.Sprintf("importing %s", lang))
+
+				switch format {
+				case "text", "txt":
+					err = d.ImportTanzilText(bufio.NewReader(r), lang, inputName(file), progress)
+				case "xml":
+					err = d.ImportTanzilXML(bufio.NewReader(r), lang, inputName(file), progress)
+				default:
+					err = fmt.Errorf("unsupported format: %q", format)
+				}
+				if err != nil {
+					return
+				}
+
+				log.Infof("imported translation for language %s", lang)
+
+				return
+			},
+		},
+	},
+}
+
+// openInput opens the file name, or the standard input if name is -.
+func openInput(name string) (io.ReadCloser, error) {
+	if name == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(name)
+}
+
+// inputName returns the name of the input name, as recorded in the
+// database: the absolute path of the file name, or stdin if name is -.
+func inputName(name string) string {
+	if name == "-" {
+		return "stdin"
+	}
+
+	if abs, err := filepath.Abs(name); err == nil {
+		return abs
+	}
+
+	return name
+}
@@ -1,15 +1,22 @@
 package cmd
 
 import (
+	"bufio"
+	"cmp"
 	"errors"
 	"fmt"
-	"net/http"
+	"io"
 	"os"
-	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/charmbracelet/log"
 	"github.com/urfave/cli/v2"
+	"github.com/vanillaiice/quran-cli/dataset"
 	"github.com/vanillaiice/quran-cli/db"
+	"github.com/vanillaiice/quran-cli/source"
 )
 
 // initCmd is the init command.
@@ -17,94 +24,535 @@ var initCmd = &cli.Command{
 	Name:    "init",
 	Aliases: []string{"i"},
 	Usage:   "initialize data for a language",
-	Flags: []cli.Flag{
-		&cli.PathFlag{
-			Name:    "data-path",
-			Aliases: []string{"p"},
-			Usage:   "save data in `PATH`",
-			Value:   "",
-		},
+	Flags: append([]cli.Flag{
+		dataPathFlag(),
 		&cli.StringFlag{
 			Name:    "language",
 			Aliases: []string{"l"},
-			Usage:   "init for language `LANGUAGE`",
+			Usage:   "init for language `LANGUAGE`, a comma separated list of languages, or all",
 			Value:   "en",
 		},
 		&cli.BoolFlag{
 			Name:    "force",
 			Aliases: []string{"f"},
-			Usage:   "delete existing data if exists",
+			Usage:   "replace existing data if exists",
 			Value:   false,
 		},
-	},
+		&cli.BoolFlag{
+			Name:    "offline",
+			Aliases: []string{"o"},
+			Usage:   "initialize from the data embedded in the binary instead of downloading it",
+		},
+		&cli.IntFlag{
+			Name:    "jobs",
+			Aliases: []string{"j"},
+			Usage:   "download at most `N` languages at a time",
+			Value:   4,
+		},
+		&cli.StringFlag{
+			Name:  "from",
+			Usage: "initialize from the local quran-json file or directory `PATH`, or - for stdin",
+		},
+	}, downloadFlags()...),
 	Action: func(ctx *cli.Context) (err error) {
-		return initFunc(langCode(ctx.String("language")), ctx.String("data-path"), ctx.Bool("force"))
+		if ctx.String("from") != "" {
+			var lang string
+			if ctx.IsSet("language") {
+				lang = ctx.String("language")
+			}
+
+			return initFrom(ctx.String("from"), lang, ctx.String("data-path"), ctx.Bool("force"))
+		}
+
+		download, err := newDownloader(ctx)
+		if err != nil {
+			return
+		}
+
+		opts := initOptions{
+			force:    ctx.Bool("force"),
+			offline:  ctx.Bool("offline"),
+			download: download,
+		}
+
+		lang := ctx.String("language")
+
+		if lang == "all" {
+			var dataPath string
+			var sources *source.Registry
+
+			if dataPath, err = getDataPath(ctx.String("data-path")); err != nil {
+				return
+			}
+
+			if sources, err = loadSources(dataPath); err != nil {
+				return
+			}
+
+			var langs []string
+			for _, src := range sources.Sources() {
+				langs = append(langs, src.Code)
+			}
+
+			return initMany(langs, dataPath, opts, ctx.Int("jobs"))
+		}
+
+		if strings.Contains(lang, ",") {
+			return initMany(splitLanguages(lang), ctx.String("data-path"), opts, ctx.Int("jobs"))
+		}
+
+		return initFunc(lang, ctx.String("data-path"), opts)
 	},
 }
 
-// initFunc downloads the needed data and initializes
+// downloadFlags returns the flags setting the downloads.
+func downloadFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.DurationFlag{
+			Name:  "timeout",
+			Usage: "abort downloads lasting longer than `DURATION`, 0 for no limit",
+			Value: source.DefaultTimeout,
+		},
+		&cli.IntFlag{
+			Name:  "retries",
+			Usage: "retry failed downloads `N` times per url",
+			Value: source.DefaultRetries,
+		},
+		&cli.StringFlag{
+			Name:    "proxy",
+			Usage:   "download through the proxy `URL`, instead of the one of HTTP(S)_PROXY",
+			EnvVars: []string{"QURAN_CLI_PROXY"},
+		},
+	}
+}
+
+// newDownloader returns a downloader set by the flags of downloadFlags.
+func newDownloader(ctx *cli.Context) (*source.Downloader, error) {
+	download, err := source.NewDownloader(ctx.Duration("timeout"), ctx.String("proxy"))
+	if err != nil {
+		return nil, err
+	}
+
+	download.Retries = ctx.Int("retries")
+	download.OnRetry = logRetry
+
+	return download, nil
+}
+
+// logRetry logs the error of a download of url before retrying it.
+func logRetry(url string, err error) {
+	log.Warnf("download of %s failed, retrying: %v", url, err)
+}
+
+// initOptions are the options of the initialization of a language.
+type initOptions struct {
+	// force replaces the existing data.
+	force bool
+	// offline reads the data embedded in the binary instead of downloading it.
+	offline bool
+	// download downloads the data, with the default settings if nil.
+	download *source.Downloader
+}
+
+// initFunc downloads the needed data, or reads it from the data
+// embedded in the binary if opts.offline is true, and initializes
 // the database for a specific language.
-var initFunc = func(lang langCode, dataPath string, force bool) (err error) {
-	switch lang {
-	case Arabic, Bengali, Chinese, English, Spanish, French, Indonesian, Russian, Swedish, Turkish, Urdu, Transliteration:
-	default:
+var initFunc = func(lang, dataPath string, opts initOptions) (err error) {
+	dataPath, err = getDataPath(dataPath)
+	if err != nil {
+		return
+	}
+
+	if opts.download == nil {
+		if opts.download, err = source.NewDownloader(source.DefaultTimeout, ""); err != nil {
+			return
+		}
+	}
+
+	opts.download.OnRetry = logRetry
+
+	sources, err := loadSources(dataPath)
+	if err != nil {
+		return
+	}
+
+	src, ok := sources.Get(lang)
+	if !ok {
 		return fmt.Errorf("unsupported language: %q", lang)
 	}
 
-	if dataPath == "" {
-		dataPath, err = os.UserHomeDir()
-		if err != nil {
+	d, err := openDb(dataPath)
+	if err != nil {
+		return
+	}
+	defer d.Close()
+
+	// translations in the tanzil formats are merged into the arabic text.
+	if src.Format != source.QuranJSON {
+		if ok, err = d.HasLanguage(db.Arabic); err != nil {
 			return
 		}
-		dataPath = path.Join(dataPath, dataDir)
+
+		if !ok {
+			log.Infof("arabic text not found, initializing it first")
+
+			arabic, _ := sources.Get(db.Arabic)
+			if err = initLanguage(d, db.Arabic, arabic.Format, arabic.URL, false, openSource(arabic, opts), progressFunc("initializing ar")); err != nil {
+				return
+			}
+
+			log.Infof("initialized quran database for language %s", db.Arabic)
+		}
 	}
 
-	dbPath := path.Join(dataPath, fmt.Sprintf("quran_%s.db", lang))
+	if err = initLanguage(d, lang, src.Format, src.URL, opts.force, openSource(src, opts), progressFunc(fmt.Sprintf("initializing %s", lang))); err != nil {
+		return
+	}
 
-	if _, err = os.Stat(dataPath); errors.Is(err, os.ErrNotExist) {
-		err = os.MkdirAll(dataPath, os.ModePerm)
-		if err != nil {
+	log.Infof("initialized quran database for language %s", lang)
+
+	return
+}
+
+// errExists is returned when initializing a language whose data exists, without forcing it.
+var errExists = errors.New("data already exists")
+
+// initMany initializes the database for the languages langs concurrently,
+// with at most jobs downloads at a time, showing their progress and then a
+// summary. The languages whose data exists are skipped, unless opts.force
+// is true. The imports are done one at a time, the arabic text first.
+func initMany(langs []string, dataPath string, opts initOptions, jobs int) (err error) {
+	dataPath, err = getDataPath(dataPath)
+	if err != nil {
+		return
+	}
+
+	if opts.download == nil {
+		if opts.download, err = source.NewDownloader(source.DefaultTimeout, ""); err != nil {
 			return
 		}
-		log.Debugf("created data directory %q", dataPath)
-	} else if err != nil {
+	}
+
+	sources, err := loadSources(dataPath)
+	if err != nil {
+		return
+	}
+
+	d, err := openDb(dataPath)
+	if err != nil {
 		return
+	}
+	defer d.Close()
+
+	hasArabic, err := d.HasLanguage(db.Arabic)
+	if err != nil {
+		return
+	}
+
+	var first, rest []string
+	for _, lang := range langs {
+		src, ok := sources.Get(lang)
+		if !ok {
+			return fmt.Errorf("unsupported language: %q", lang)
+		}
+
+		if lang == db.Arabic {
+			first = append(first, lang)
+		} else {
+			rest = append(rest, lang)
+
+			// translations in the tanzil formats are merged into the arabic text.
+			if src.Format != source.QuranJSON && !hasArabic && !slices.Contains(langs, db.Arabic) && len(first) == 0 {
+				first = []string{db.Arabic}
+			}
+		}
+	}
+
+	langs = append(first, rest...)
+	board := newProgressBoard(langs)
+	results := make(map[string]error, len(langs))
+
+	var (
+		mu        sync.Mutex
+		importing sync.Mutex
+	)
+
+	// run initializes the languages concurrently.
+	run := func(langs []string) {
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, max(jobs, 1))
+
+		for _, lang := range langs {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				src, _ := sources.Get(lang)
+				err := initSource(d, src, opts, board, &importing)
+
+				mu.Lock()
+				results[lang] = err
+				mu.Unlock()
+
+				switch {
+				case err == nil:
+					board.set(lang, "done")
+				case errors.Is(err, errExists):
+					board.set(lang, "skipped, already initialized")
+				default:
+					board.set(lang, "failed")
+				}
+			}()
+		}
+
+		wg.Wait()
+	}
+
+	run(first)
+	run(rest)
+
+	var failed int
+
+	for _, lang := range langs {
+		switch err := results[lang]; {
+		case err == nil:
+			log.Infof("initialized quran database for language %s", lang)
+		case errors.Is(err, errExists):
+			log.Warnf("skipped language %s: data already exists, use --force to replace it", lang)
+		default:
+			failed++
+			log.Errorf("failed to initialize language %s: %v", lang, err)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d languages failed to initialize", failed, len(langs))
+	}
+
+	return
+}
+
+// initSource downloads the data of the source src, then initializes the
+// database for its language, holding importing during the import and
+// showing the progress on board.
+func initSource(d *db.Conn, src source.Source, opts initOptions, board *progressBoard, importing *sync.Mutex) (err error) {
+	lang := src.Code
+
+	ok, err := d.HasLanguage(lang)
+	if err != nil {
+		return
+	}
+
+	if ok && !opts.force {
+		return errExists
+	}
+
+	download := *opts.download
+	download.OnRetry = func(url string, err error) {
+		board.set(lang, fmt.Sprintf("retrying: %v", err))
+	}
+	download.OnProgress = func(url string, done, total int64) {
+		if total > 0 {
+			board.set(lang, fmt.Sprintf("downloading %3d%% of %s", done*100/total, formatBytes(total)))
+		} else {
+			board.set(lang, fmt.Sprintf("downloading %s", formatBytes(done&^(1<<16-1))))
+		}
+	}
+	opts.download = &download
+
+	board.set(lang, "downloading")
+
+	r, err := openSource(src, opts)()
+	if err != nil {
+		return
+	}
+	defer r.Close()
+
+	board.set(lang, "waiting to import")
+
+	importing.Lock()
+	defer importing.Unlock()
+
+	return initLanguage(d, lang, src.Format, src.URL, opts.force, func() (io.ReadCloser, error) {
+		return r, nil
+	}, func(done, total int) {
+		board.set(lang, fmt.Sprintf("importing %d/%d verses", done, total))
+	})
+}
+
+// openSource returns a function opening the data of the source src, reading
+// it from the data embedded in the binary if it is, or if opts.offline is
+// true, only quran-json data being embedded.
+func openSource(src source.Source, opts initOptions) func() (io.ReadCloser, error) {
+	return func() (io.ReadCloser, error) {
+		if opts.offline && src.Format != source.QuranJSON {
+			return nil, fmt.Errorf("language %s: %s data is not embedded, initialize it without --offline", src.Code, src.Format)
+		}
+
+		if opts.offline || embedded(src) {
+			r, err := dataset.Open(src.Code)
+			if err != nil {
+				return nil, fmt.Errorf("language %s: %w", src.Code, err)
+			}
+			return r, nil
+		}
+
+		log.Debugf("fetching %s...", src.URL)
+
+		r, err := opts.download.Open(src)
+		if err != nil {
+			return nil, err
+		}
+
+		log.Debugf("fetched %s", src.URL)
+
+		return r, nil
+	}
+}
+
+// embedded returns true if the data of the source src is embedded
+// in the binary, src being a built-in source left unchanged.
+func embedded(src source.Source) bool {
+	builtin, ok := source.Builtin().Get(src.Code)
+	return ok && builtin.URL == src.URL && src.Format == source.QuranJSON && dataset.Has(src.Code)
+}
+
+// initFrom initializes the database from the local quran-json data at path:
+// a file, a directory of files named as quran-json ones (quran.json for the
+// arabic text, quran_<lang>.json for the translations), or - for the
+// standard input. The data of a single file or of the standard input is
+// stored under the language lang if not empty, else under the language of
+// the name of the file, defaulting to english.
+func initFrom(path, lang, dataPath string, force bool) (err error) {
+	files := map[string]string{}
+
+	if path == "-" {
+		files[cmp.Or(lang, defaultLanguage)] = path
 	} else {
-		if _, err = os.Stat(dbPath); err == nil {
-			if force {
-				if err = os.Remove(dbPath); err != nil {
-					return
+		var info os.FileInfo
+
+		if info, err = os.Stat(path); err != nil {
+			return
+		}
+
+		if info.IsDir() {
+			var matches []string
+
+			if matches, err = filepath.Glob(filepath.Join(path, "quran*.json")); err != nil {
+				return
+			}
+
+			for _, m := range matches {
+				if l, ok := fileLanguage(m); ok {
+					files[l] = m
+				} else {
+					log.Warnf("skipping file %q, not named as quran-json data", m)
 				}
-				log.Warnf("deleted existing database for language %s", lang)
-			} else {
-				return fmt.Errorf("database already exists for language %s", lang)
 			}
+
+			if len(files) == 0 {
+				return fmt.Errorf("no quran-json data found in %q", path)
+			}
+		} else if l, ok := fileLanguage(path); ok && lang == "" {
+			files[l] = path
+		} else {
+			files[cmp.Or(lang, defaultLanguage)] = path
 		}
 	}
 
-	log.Debugf("downloading file quran_%s.json...", lang)
+	dataPath, err = getDataPath(dataPath)
+	if err != nil {
+		return
+	}
 
-	resp, err := http.Get(sources[lang])
+	d, err := openDb(dataPath)
 	if err != nil {
 		return
 	}
-	defer resp.Body.Close()
+	defer d.Close()
+
+	langs := make([]string, 0, len(files))
+	for l := range files {
+		if !source.ValidCode(l) {
+			return fmt.Errorf("invalid language code: %q", l)
+		}
+		langs = append(langs, l)
+	}
 
-	log.Debugf("downloaded file quran_%s.json", lang)
+	// the arabic text is imported first, the translations relying on it.
+	slices.SortFunc(langs, func(a, b string) int {
+		return cmp.Or(arabicFirst(a, b), strings.Compare(a, b))
+	})
 
-	d, err := db.New(dbPath)
+	for _, l := range langs {
+		file := files[l]
+
+		if err = initLanguage(d, l, source.QuranJSON, inputName(file), force, func() (io.ReadCloser, error) {
+			return openInput(file)
+		}, progressFunc(fmt.Sprintf("initializing %s", l))); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+
+		log.Infof("initialized quran database for language %s", l)
+	}
+
+	return
+}
+
+// fileLanguage returns the language of a quran-json file from its name.
+func fileLanguage(file string) (lang string, ok bool) {
+	name := filepath.Base(file)
+
+	if name == "quran.json" {
+		return db.Arabic, true
+	}
+
+	lang, ok = strings.CutPrefix(strings.TrimSuffix(name, ".json"), "quran_")
+
+	return lang, ok && strings.HasSuffix(name, ".json") && lang != ""
+}
+
+// initLanguage initializes the database for the language lang from the
+// data in the format format returned by open, recording origin as its
+// source and calling progress, if not nil, with the progress of the import.
+// Existing data is replaced in the transaction of the import, and is left
+// unchanged if it fails.
+func initLanguage(d *db.Conn, lang string, format source.Format, origin string, force bool, open func() (io.ReadCloser, error), progress db.Progress) (err error) {
+	ok, err := d.HasLanguage(lang)
 	if err != nil {
 		return
 	}
-	defer d.Close()
 
-	log.Debugf("intializing quran database for language %s...", lang)
+	if ok && !force {
+		return fmt.Errorf("data already exists for language %s", lang)
+	}
 
-	if err = d.InitFromReader(resp.Body); err != nil {
+	r, err := open()
+	if err != nil {
 		return
 	}
+	defer r.Close()
 
-	log.Infof("initialized quran database for language %s", lang)
+	log.Debugf("intializing quran database for language %s...", lang)
 
-	return
+	return importData(d, bufio.NewReader(r), lang, format, origin, progress)
+}
+
+// importData imports the data in the format format read
+// from r under the language lang, imported from origin.
+func importData(d *db.Conn, r io.Reader, lang string, format source.Format, origin string, progress db.Progress) error {
+	switch format {
+	case source.QuranJSON:
+		return d.InitFromReader(r, lang, origin, progress)
+	case source.TanzilText:
+		return d.ImportTanzilText(r, lang, origin, progress)
+	case source.TanzilXML:
+		return d.ImportTanzilXML(r, lang, origin, progress)
+	default:
+		return fmt.Errorf("unsupported format: %q", format)
+	}
 }
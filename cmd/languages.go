@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v2"
+	"github.com/vanillaiice/quran-cli/dataset"
+	"github.com/vanillaiice/quran-cli/source"
+)
+
+// languagesCmd is the languages command.
+// It lists the known languages and the installed ones.
+var languagesCmd = &cli.Command{
+	Name:    "languages",
+	Aliases: []string{"ls", "list"},
+	Usage:   "list the known and installed languages",
+	Flags: []cli.Flag{
+		dataPathFlag(),
+		&cli.BoolFlag{
+			Name:    "installed",
+			Aliases: []string{"i"},
+			Usage:   "list the installed languages only",
+		},
+		&cli.BoolFlag{
+			Name:  "json",
+			Usage: "print the languages in JSON",
+		},
+	},
+	Action: func(ctx *cli.Context) (err error) {
+		dataPath, err := getDataPath(ctx.String("data-path"))
+		if err != nil {
+			return
+		}
+
+		languages, err := listLanguages(dataPath, ctx.Bool("installed"))
+		if err != nil {
+			return
+		}
+
+		if ctx.Bool("json") {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(languages)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+		fmt.Fprintln(w, "CODE\tNAME\tTRANSLATOR\tINSTALLED\tVERSES\tSIZE\tIMPORTED\tSOURCE")
+
+		for _, l := range languages {
+			installed, verses, size, imported, src := "no", "-", "-", "-", l.URL
+
+			if l.Installed {
+				installed, verses, size = "yes", fmt.Sprint(l.Verses), formatBytes(l.Size)
+
+				if l.ImportedAt != nil {
+					imported = l.ImportedAt.Local().Format("2006-01-02 15:04")
+				}
+
+				src = cmp.Or(l.Source, "-")
+			} else if l.Embedded {
+				installed = "embedded"
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", l.Code, l.Name, l.Translator, installed, verses, size, imported, src)
+		}
+
+		if err = w.Flush(); err != nil {
+			return
+		}
+
+		if info, err := os.Stat(path.Join(dataPath, dbName)); err == nil {
+			fmt.Printf("\ndatabase %s, %s\n", path.Join(dataPath, dbName), formatBytes(info.Size()))
+		}
+
+		return
+	},
+}
+
+// language describes a known or installed language.
+type language struct {
+	Code       string        `json:"code"`
+	Name       string        `json:"name,omitempty"`
+	Translator string        `json:"translator,omitempty"`
+	URL        string        `json:"url,omitempty"`
+	Format     source.Format `json:"format,omitempty"`
+	// Embedded is true if the data is embedded in the binary.
+	Embedded  bool `json:"embedded"`
+	Installed bool `json:"installed"`
+	Verses    int  `json:"verses,omitempty"`
+	Surahs    int  `json:"surahs,omitempty"`
+	// Size is the size of the text of the verses, in bytes.
+	Size       int64      `json:"size,omitempty"`
+	ImportedAt *time.Time `json:"imported_at,omitempty"`
+	// Source is the location the data was imported from.
+	Source string `json:"source,omitempty"`
+}
+
+// listLanguages returns the languages of the sources of dataPath and the
+// ones installed in its database, sorted by code, or the installed ones
+// only if installed is true.
+func listLanguages(dataPath string, installed bool) (languages []*language, err error) {
+	sources, err := loadSources(dataPath)
+	if err != nil {
+		return
+	}
+
+	d, err := openDb(dataPath)
+	if err != nil {
+		return
+	}
+	defer d.Close()
+
+	infos, err := d.LanguageInfos()
+	if err != nil {
+		return
+	}
+
+	byCode := make(map[string]*language)
+
+	for _, src := range sources.Sources() {
+		byCode[src.Code] = &language{
+			Code:       src.Code,
+			Name:       src.Name,
+			Translator: src.Translator,
+			URL:        src.URL,
+			Format:     src.Format,
+			Embedded:   dataset.Has(src.Code),
+		}
+	}
+
+	for _, info := range infos {
+		l, ok := byCode[info.Lang]
+		if !ok {
+			l = &language{Code: info.Lang}
+			byCode[info.Lang] = l
+		}
+
+		l.Installed = true
+		l.Verses, l.Surahs, l.Size, l.Source = info.Verses, info.Surahs, info.Size, info.Source
+
+		if !info.ImportedAt.IsZero() {
+			l.ImportedAt = &info.ImportedAt
+		}
+	}
+
+	for _, l := range byCode {
+		if l.Installed || !installed {
+			languages = append(languages, l)
+		}
+	}
+
+	sort.Slice(languages, func(i, j int) bool {
+		return languages[i].Code < languages[j].Code
+	})
+
+	return
+}
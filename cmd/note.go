@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/urfave/cli/v2"
+	"github.com/vanillaiice/quran-cli/tui"
+	"github.com/vanillaiice/quran-cli/user"
+)
+
+// noteCmd is the note command.
+// It manages the personal notes about verses.
+var noteCmd = &cli.Command{
+	Name:    "note",
+	Aliases: []string{"n"},
+	Usage:   "manage notes about verses",
+	Subcommands: []*cli.Command{
+		{
+			Name:      "edit",
+			Aliases:   []string{"e"},
+			Usage:     "edit the note of a verse with $EDITOR",
+			ArgsUsage: "REFERENCE",
+			Flags:     []cli.Flag{dataPathFlag()},
+			Action: func(ctx *cli.Context) (err error) {
+				u, surahId, verseId, err := openNote(ctx)
+				if err != nil {
+					return
+				}
+				defer u.Close()
+
+				text, err := editNoteFunc(u)(surahId, verseId)
+				if err != nil {
+					return
+				}
+
+				if text == "" {
+					fmt.Printf("removed note of %d:%d\n", surahId, verseId)
+				} else {
+					fmt.Printf("saved note of %d:%d\n", surahId, verseId)
+				}
+
+				return
+			},
+		},
+		{
+			Name:      "show",
+			Aliases:   []string{"s"},
+			Usage:     "show the note of a verse",
+			ArgsUsage: "REFERENCE",
+			Flags:     []cli.Flag{dataPathFlag()},
+			Action: func(ctx *cli.Context) (err error) {
+				u, surahId, verseId, err := openNote(ctx)
+				if err != nil {
+					return
+				}
+				defer u.Close()
+
+				n, err := u.GetNote(surahId, verseId)
+				if errors.Is(err, user.ErrNotFound) {
+					return fmt.Errorf("no note for %d:%d", surahId, verseId)
+				} else if err != nil {
+					return
+				}
+
+				fmt.Printf("%d:%d (%s)\n%s\n", n.SurahId, n.VerseId, n.UpdatedAt.Format("2006-01-02 15:04"), n.Text)
+
+				return
+			},
+		},
+		{
+			Name:    "list",
+			Aliases: []string{"l"},
+			Usage:   "list notes",
+			Flags:   []cli.Flag{dataPathFlag()},
+			Action: func(ctx *cli.Context) (err error) {
+				dataPath, err := getDataPath(ctx.String("data-path"))
+				if err != nil {
+					return
+				}
+
+				u, err := openUserDb(dataPath)
+				if err != nil {
+					return
+				}
+				defer u.Close()
+
+				notes, err := u.GetNotes()
+				if err != nil {
+					return
+				}
+
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+				for _, n := range notes {
+					line, _, _ := strings.Cut(n.Text, "\n")
+					fmt.Fprintf(w, "%d:%d\t%s\t%s\n", n.SurahId, n.VerseId, n.UpdatedAt.Format("2006-01-02 15:04"), line)
+				}
+
+				return w.Flush()
+			},
+		},
+		{
+			Name:      "remove",
+			Aliases:   []string{"rm"},
+			Usage:     "remove the note of a verse",
+			ArgsUsage: "REFERENCE",
+			Flags:     []cli.Flag{dataPathFlag()},
+			Action: func(ctx *cli.Context) (err error) {
+				u, surahId, verseId, err := openNote(ctx)
+				if err != nil {
+					return
+				}
+				defer u.Close()
+
+				if err = u.RemoveNote(surahId, verseId); errors.Is(err, user.ErrNotFound) {
+					return fmt.Errorf("no note for %d:%d", surahId, verseId)
+				}
+
+				return
+			},
+		},
+	},
+}
+
+// openNote opens the user data database and resolves
+// the verse referenced by the argument of a note command.
+func openNote(ctx *cli.Context) (u *user.Conn, surahId, verseId int, err error) {
+	if ctx.NArg() != 1 {
+		err = fmt.Errorf("please specify a verse")
+		return
+	}
+
+	dataPath, err := getDataPath(ctx.String("data-path"))
+	if err != nil {
+		return
+	}
+
+	surahId, verseId, err = resolveVerse(dataPath, ctx.Args().First())
+	if err != nil {
+		return
+	}
+
+	u, err = openUserDb(dataPath)
+
+	return
+}
+
+// editNoteFunc returns a function editing the notes of verses with the user's editor.
+func editNoteFunc(u *user.Conn) func(surahId, verseId int) (string, error) {
+	return func(surahId, verseId int) (string, error) {
+		var text string
+
+		n, err := u.GetNote(surahId, verseId)
+		if err == nil {
+			text = n.Text
+		} else if !errors.Is(err, user.ErrNotFound) {
+			return "", err
+		}
+
+		if text, err = editText(text); err != nil {
+			return "", err
+		}
+
+		if err = u.SetNote(surahId, verseId, text); err != nil {
+			return "", err
+		}
+
+		return text, nil
+	}
+}
+
+// setNotes loads the notes in the configuration of a reading session.
+func setNotes(u *user.Conn, cfg *tui.Config) error {
+	notes, err := u.GetNotes()
+	if err != nil {
+		return err
+	}
+
+	cfg.Notes = make(map[tui.VerseKey]string, len(notes))
+	for _, n := range notes {
+		cfg.Notes[tui.VerseKey{Surah: n.SurahId, Verse: n.VerseId}] = n.Text
+	}
+
+	cfg.EditNote = editNoteFunc(u)
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/vanillaiice/quran-cli/db"
+	"golang.org/x/term"
+)
+
+// progressFunc returns a function showing the progress of
+// an import labelled label, if stderr is a terminal.
+func progressFunc(label string) db.Progress {
+	if !term.IsTerminal(int(os.Stderr.Fd())) {
+		return nil
+	}
+
+	last := -1
+
+	return func(done, total int) {
+		percent := done * 100 / total
+		if percent == last {
+			return
+		}
+		last = percent
+
+		fmt.Fprintf(os.Stderr, "\r%s %3d%%", label, percent)
+
+		if done == total {
+			fmt.Fprintln(os.Stderr)
+		}
+	}
+}
+
+// progressBoard shows the status of concurrent tasks, one
+// line per task, redrawn in place if stderr is a terminal.
+type progressBoard struct {
+	mu       sync.Mutex
+	labels   []string
+	statuses map[string]string
+	tty      bool
+	drawn    bool
+}
+
+// newProgressBoard returns a board of the tasks labelled labels.
+func newProgressBoard(labels []string) *progressBoard {
+	b := &progressBoard{
+		labels:   labels,
+		statuses: make(map[string]string, len(labels)),
+		tty:      term.IsTerminal(int(os.Stderr.Fd())),
+	}
+
+	for _, l := range labels {
+		b.statuses[l] = "waiting"
+	}
+
+	return b
+}
+
+// set sets the status of the task labelled label, redrawing the board if it changed.
+func (b *progressBoard) set(label, status string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.statuses[label] == status {
+		return
+	}
+	b.statuses[label] = status
+
+	if !b.tty {
+		return
+	}
+
+	width := 80
+	if w, _, err := term.GetSize(int(os.Stderr.Fd())); err == nil && w > 0 {
+		width = w
+	}
+
+	if b.drawn {
+		fmt.Fprintf(os.Stderr, "\x1b[%dA", len(b.labels))
+	}
+	b.drawn = true
+
+	pad := 0
+	for _, l := range b.labels {
+		pad = max(pad, len(l))
+	}
+
+	for _, l := range b.labels {
+		line := fmt.Sprintf("%-*s  %s", pad, l, b.statuses[l])
+		fmt.Fprintf(os.Stderr, "\x1b[2K%s\n", runewidth.Truncate(line, width-1, ""))
+	}
+}
+
+// formatBytes formats a number of bytes n in a human readable way.
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
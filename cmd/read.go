@@ -4,34 +4,28 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
-	"os"
-	"path"
 	"strings"
 
 	"github.com/charmbracelet/log"
 	"github.com/urfave/cli/v2"
 	"github.com/vanillaiice/quran-cli/db"
+	"github.com/vanillaiice/quran-cli/ref"
+	"github.com/vanillaiice/quran-cli/source"
 	"github.com/vanillaiice/quran-cli/tui"
 	"github.com/vanillaiice/quran-cli/tui/list"
 	"github.com/vanillaiice/quran-cli/tui/tview"
+	"github.com/vanillaiice/quran-cli/user"
 )
 
-// maxSurahId is the maximum surah id in the Quran.
-const maxSurahId = 114
-
 // readCmd is the read command.
 // It prints a surah by providing its name or number.
 var readCmd = &cli.Command{
-	Name:    "read",
-	Aliases: []string{"r"},
-	Usage:   "read a surah",
+	Name:      "read",
+	Aliases:   []string{"r"},
+	Usage:     "read a surah",
+	ArgsUsage: "[REFERENCE...]",
 	Flags: []cli.Flag{
-		&cli.PathFlag{
-			Name:    "data-path",
-			Aliases: []string{"p"},
-			Usage:   "data path `PATH`",
-			Value:   "",
-		},
+		dataPathFlag(),
 		&cli.StringFlag{
 			Name:    "style",
 			Aliases: []string{"t"},
@@ -47,7 +41,7 @@ var readCmd = &cli.Command{
 		&cli.StringFlag{
 			Name:    "mode",
 			Aliases: []string{"m"},
-			Usage:   "reading mode `MODE` (arabic, translation, both)",
+			Usage:   "reading mode `MODE` (arabic, translation, both, interlinear)",
 			Value:   "both",
 		},
 		&cli.StringFlag{
@@ -71,41 +65,143 @@ var readCmd = &cli.Command{
 			Aliases: []string{"r"},
 			Usage:   "read a random surah",
 		},
+		&cli.IntFlag{
+			Name:    "juz",
+			Aliases: []string{"j"},
+			Usage:   "read juz number `NUMBER`",
+		},
+		&cli.IntFlag{
+			Name:  "hizb",
+			Usage: "read hizb number `NUMBER`",
+		},
+		&cli.IntFlag{
+			Name:  "hizb-quarter",
+			Usage: "read hizb quarter number `NUMBER`",
+		},
+		&cli.IntFlag{
+			Name:  "manzil",
+			Usage: "read manzil number `NUMBER`",
+		},
+		&cli.IntFlag{
+			Name:  "page",
+			Usage: "read page number `NUMBER` of the madani mushaf",
+		},
+		&cli.StringFlag{
+			Name:    "bookmark",
+			Aliases: []string{"b"},
+			Usage:   "read the surah of bookmark `NAME`, starting at its verse",
+		},
+		&cli.BoolFlag{
+			Name:    "resume",
+			Aliases: []string{"c"},
+			Usage:   "resume reading where the last session ended",
+		},
+		&cli.BoolFlag{
+			Name:    "auto-resume",
+			Usage:   "resume reading when no surah is given",
+			EnvVars: []string{"QURAN_CLI_AUTO_RESUME"},
+			Value:   true,
+		},
+		&cli.StringFlag{
+			Name:  "tafsir",
+			Usage: "show the commentary of tafsir `NAME` in the tview style",
+		},
 	},
 	Action: func(ctx *cli.Context) (err error) {
-		lang := langCode(ctx.String("language"))
-		switch lang {
-		case Arabic, Bengali, Chinese, English, Spanish, French, Indonesian, Russian, Swedish, Turkish, Urdu, Transliteration:
-		default:
-			return fmt.Errorf("unsupported language: %q", lang)
+		dataPath, err := getDataPath(ctx.String("data-path"))
+		if err != nil {
+			return
 		}
 
-		var mode tui.Lang
-		switch ctx.String("mode") {
-		case "arabic", "ar":
-			mode = tui.Arabic
-		case "translation", "tr":
-			mode = tui.Translation
-		case "both", "bo":
-			mode = tui.Both
-		default:
-			return fmt.Errorf("unsupported mode: %q", ctx.String("mode"))
+		u, err := openUserDb(dataPath)
+		if err != nil {
+			return
 		}
+		defer u.Close()
+
+		lang := ctx.String("language")
+		modeName := ctx.String("mode")
 
-		dataPath := ctx.String("data-path")
+		// position of the verse to start reading at, if any.
+		var startSurah, startVerse int
+
+		// division to read, if any.
+		var division *db.Division
+		for _, div := range []*db.Division{db.Juz, db.Hizb, db.HizbQuarter, db.Manzil, db.Page} {
+			if ctx.IsSet(divisionFlag(div)) {
+				division = div
+			}
+		}
 
-		if dataPath == "" {
-			dataPath, err = os.UserHomeDir()
+		if ctx.String("bookmark") != "" {
+			var b *user.Bookmark
+
+			b, err = getBookmark(u, ctx.String("bookmark"))
 			if err != nil {
 				return
 			}
-			dataPath = path.Join(dataPath, dataDir)
+
+			startSurah, startVerse = b.SurahId, b.VerseId
+		} else if ctx.Bool("resume") || (ctx.Bool("auto-resume") && !ctx.Args().Present() && !ctx.IsSet("surah") && !ctx.IsSet("number") && !ctx.Bool("random") && division == nil) {
+			var p *user.Position
+
+			p, err = u.GetLastRead()
+			if err == nil {
+				startSurah, startVerse = p.SurahId, p.VerseId
+
+				if !ctx.IsSet("language") {
+					lang = p.Lang
+				}
+
+				if !ctx.IsSet("mode") {
+					modeName = p.Mode
+				}
+			} else if errors.Is(err, user.ErrNotFound) && !ctx.Bool("resume") {
+				err = nil
+			} else if errors.Is(err, user.ErrNotFound) {
+				return fmt.Errorf("no reading session to resume")
+			} else {
+				return
+			}
+		}
+
+		mode, err := parseMode(modeName)
+		if err != nil {
+			return
+		}
+
+		d, err := openDb(dataPath)
+		if err != nil {
+			return
+		}
+		defer d.Close()
+
+		sources, err := loadSources(dataPath)
+		if err != nil {
+			return
+		}
+
+		src, known := sources.Get(lang)
+
+		ok, err := d.HasLanguage(lang)
+		if err != nil {
+			return
 		}
 
-		dbPath := path.Join(dataPath, fmt.Sprintf("quran_%s.db", lang))
+		if !ok {
+			if !known {
+				return fmt.Errorf("unsupported language: %q", lang)
+			}
+
+			offline := embedded(src)
 
-		if _, err = os.Stat(dbPath); errors.Is(err, os.ErrNotExist) {
-			fmt.Printf("database %q not found, create it ? (y/N)\n -> ", dbPath)
+			if offline {
+				fmt.Printf("data for language %s not found, initialize it from the embedded data ? (y/N)\n -> ", lang)
+			} else if !src.Remote() {
+				fmt.Printf("data for language %s not found, initialize it from %s ? (y/N)\n -> ", lang, src.URL)
+			} else {
+				fmt.Printf("data for language %s not found, download it ? (y/N)\n -> ", lang)
+			}
 
 			var ans string
 			_, err = fmt.Scan(&ans)
@@ -115,69 +211,309 @@ var readCmd = &cli.Command{
 			ans = strings.ToLower(ans)
 
 			if ans == "y" || ans == "yes" {
-				err = initFunc(lang, dataPath, true)
+				err = initFunc(lang, dataPath, initOptions{force: true, offline: offline})
 				if err != nil {
 					return
 				}
 			} else {
-				log.Warn("not creating database")
+				log.Warn("not downloading data")
 				return
 			}
 		}
 
-		d, err := db.New(dbPath)
+		cfg, err := readConfig(d, u, sources, lang, mode, ctx.String("tafsir"))
+		if err != nil {
+			return
+		}
+
+		opts := readOptions{
+			refs:       strings.Join(ctx.Args().Slice(), ","),
+			surah:      ctx.String("surah"),
+			exact:      ctx.Bool("exact"),
+			number:     ctx.Int("number"),
+			random:     ctx.Bool("random"),
+			division:   division,
+			startSurah: startSurah,
+			startVerse: startVerse,
+		}
+
+		if division != nil {
+			opts.part = ctx.Int(divisionFlag(division))
+		}
+
+		surahs, start, err := readSurahs(d, lang, opts)
+		if err != nil {
+			return
+		}
+		cfg.Start = start
+
+		if mode == tui.Interlinear {
+			if err = loadWords(d, surahs); err != nil {
+				return
+			}
+		}
+
+		return runTui(ctx.String("style"), surahs, cfg)
+	},
+}
+
+// readConfig returns the configuration of a reading session in language
+// lang of sources and mode mode, bookmarking verses and saving its end in
+// the user database u, and showing the notes and the commentaries of the
+// tafsir named tafsir, or of the first one if empty.
+func readConfig(d db.Store, u *user.Conn, sources *source.Registry, lang string, mode tui.Lang, tafsir string) (cfg tui.Config, err error) {
+	src, _ := sources.Get(lang)
+
+	cfg = tui.Config{
+		Lang:     mode,
+		RTL:      src.Direction == source.RTL,
+		Bookmark: bookmarkFunc(u),
+		Quit:     lastReadFunc(u, lang, mode),
+	}
+
+	if err = setNotes(u, &cfg); err != nil {
+		return
+	}
+
+	err = setTafsir(d, &cfg, tafsir, lang)
+
+	return
+}
+
+// lastReadFunc returns a function saving the verse a
+// reading session in language lang and mode mode ended at.
+func lastReadFunc(u *user.Conn, lang string, mode tui.Lang) func(surahId, verseId int) error {
+	return func(surahId, verseId int) error {
+		return u.SetLastRead(&user.Position{SurahId: surahId, VerseId: verseId, Lang: lang, Mode: mode.String()})
+	}
+}
+
+// parseMode parses a reading mode.
+func parseMode(s string) (mode tui.Lang, err error) {
+	switch s {
+	case "arabic", "ar":
+		mode = tui.Arabic
+	case "translation", "tr":
+		mode = tui.Translation
+	case "both", "bo":
+		mode = tui.Both
+	case "interlinear", "il":
+		mode = tui.Interlinear
+	default:
+		err = fmt.Errorf("unsupported mode: %q", s)
+	}
+	return
+}
+
+// runTui displays the surahs in the terminal ui style style.
+func runTui(style string, surahs []*db.Surah, cfg tui.Config) (err error) {
+	switch style {
+	case "tview", "tv":
+		err = tview.Run(surahs, cfg)
+	case "list", "li":
+		err = list.Run(surahs, cfg)
+	default:
+		err = fmt.Errorf("invalid style: %q", style)
+	}
+	return
+}
+
+// divisionFlag returns the name of the flag reading a part of the division d.
+func divisionFlag(d *db.Division) string {
+	return strings.ReplaceAll(d.Name, " ", "-")
+}
+
+// readOptions are the options selecting the surahs to read, by order of precedence.
+type readOptions struct {
+	// division is the division to read, if any, and part the number of its part.
+	division *db.Division
+	part     int
+	// startSurah and startVerse are the position of
+	// the verse to start reading at, if any.
+	startSurah int
+	startVerse int
+	// refs is the list of references to read, if any.
+	refs   string
+	random bool
+	// surah is the name of the surah to read, matched exactly if exact is true.
+	surah  string
+	exact  bool
+	number int
+}
+
+// readSurahs returns the surahs selected by opts, with their translation in
+// the language lang, and the index of the verse to start reading at.
+func readSurahs(d db.Store, lang string, opts readOptions) (surahs []*db.Surah, start int, err error) {
+	if opts.division != nil {
+		var span db.Span
+
+		span, err = opts.division.Span(opts.part)
+		if err != nil {
+			return
+		}
+
+		surahs, err = d.GetSpan(span, lang)
 		if err != nil {
 			return
 		}
-		defer d.Close()
 
+		if len(surahs) == 0 {
+			return nil, 0, fmt.Errorf("%s %d not found", opts.division.Name, opts.part)
+		}
+
+		return
+	}
+
+	if opts.startSurah != 0 {
 		var surah *db.Surah
 
-		if ctx.Bool("random") {
-			surah, err = d.GetSurahById(rand.Intn(maxSurahId) + 1)
+		surah, err = d.GetSurahById(opts.startSurah, lang)
+		if err != nil {
+			return
+		}
+
+		if len(surah.Verses) == 0 {
+			return nil, 0, fmt.Errorf("surah #%d not found", opts.startSurah)
+		}
+
+		surahs = append(surahs, surah)
+
+		return surahs, tui.Index(surahs, opts.startSurah, opts.startVerse), nil
+	}
+
+	if opts.refs != "" {
+		surahs, err = readRefs(d, opts.refs, lang, opts.exact)
+		return
+	}
+
+	var surah *db.Surah
+
+	if opts.random {
+		surah, err = d.GetSurahById(rand.Intn(ref.MaxSurahId)+1, lang)
+		if err != nil {
+			return
+		}
+	} else if opts.surah != "" {
+		if !opts.exact {
+			var id int
+
+			id, err = d.ResolveSurah(opts.surah, lang)
+			if err != nil {
+				return
+			}
+
+			surah, err = d.GetSurahById(id, lang)
 			if err != nil {
 				return
 			}
 		} else {
-			if ctx.String("surah") != "" {
-				if !ctx.Bool("exact") {
-					surah, err = d.GetSurahByNameLike(ctx.String("surah"))
-					if err != nil {
-						return
-					}
-				} else {
-					surah, err = d.GetSurahByName(ctx.String("surah"))
-					if err != nil {
-						return
-					}
-				}
+			surah, err = d.GetSurahByName(opts.surah, lang)
+			if err != nil {
+				return
+			}
+		}
 
-				if len(surah.Verses) == 0 {
-					return fmt.Errorf("surah %q not found", ctx.String("surah"))
-				}
-			} else if ctx.Int("number") != 0 {
-				surah, err = d.GetSurahById(ctx.Int("number"))
+		if len(surah.Verses) == 0 {
+			return nil, 0, fmt.Errorf("surah %q not found", opts.surah)
+		}
+	} else if opts.number != 0 {
+		surah, err = d.GetSurahById(opts.number, lang)
+		if err != nil {
+			return
+		}
+
+		if len(surah.Verses) == 0 {
+			return nil, 0, fmt.Errorf("surah #%d not found", opts.number)
+		}
+	} else {
+		return nil, 0, fmt.Errorf("please specify surah name or number")
+	}
+
+	return append(surahs, surah), 0, nil
+}
+
+// loadWords loads the words of the verses of the surahs, for interlinear reading.
+func loadWords(d db.Store, surahs []*db.Surah) (err error) {
+	ok, err := d.HasWords()
+	if err != nil {
+		return
+	}
+
+	if !ok {
+		return fmt.Errorf("word-by-word data not found, import it with the import words command")
+	}
+
+	// the words of the verses of each surah are loaded at once.
+	for _, s := range surahs {
+		if len(s.Verses) == 0 {
+			continue
+		}
+
+		var words map[db.Position][]db.Word
+
+		words, err = d.GetSpanWords(db.Span{
+			From: db.Position{Surah: s.Id, Verse: s.Verses[0].Id},
+			To:   db.Position{Surah: s.Id, Verse: s.Verses[len(s.Verses)-1].Id},
+		})
+		if err != nil {
+			return
+		}
+
+		for i, v := range s.Verses {
+			s.Verses[i].Words = words[db.Position{Surah: s.Id, Verse: v.Id}]
+		}
+	}
+
+	return
+}
+
+// readRefs returns the surahs, or the ranges of verses
+// of surahs, referenced by the reference list s.
+func readRefs(d db.Store, s, lang string, exact bool) (surahs []*db.Surah, err error) {
+	refs, err := ref.Parse(s)
+	if err != nil {
+		return
+	}
+
+	for _, r := range refs {
+		id := r.Surah
+
+		if r.Name != "" {
+			if exact {
+				var named *db.Surah
+
+				named, err = d.GetSurahByName(r.Name, lang)
 				if err != nil {
 					return
 				}
 
-				if len(surah.Verses) == 0 {
-					return fmt.Errorf("surah #%d not found", ctx.Int("number"))
+				if len(named.Verses) == 0 {
+					return nil, fmt.Errorf("surah %q not found", r.Name)
 				}
-			} else {
-				return fmt.Errorf("please specify surah name or number")
+
+				id = named.Id
+			} else if id, err = d.ResolveSurah(r.Name, lang); err != nil {
+				return
 			}
 		}
 
-		switch ctx.String("style") {
-		case "tview", "tv":
-			err = tview.Run(surah, mode)
-		case "list", "li":
-			err = list.Run(surah, mode)
-		default:
-			err = fmt.Errorf("invalid style: %q", ctx.String("style"))
+		var surah *db.Surah
+
+		if r.Whole() {
+			surah, err = d.GetSurahById(id, lang)
+		} else {
+			surah, err = d.GetVerses(id, r.From, r.To, lang)
+		}
+		if err != nil {
+			return
 		}
 
-		return
-	},
+		if len(surah.Verses) == 0 || (!r.Whole() && len(surah.Verses) != r.To-r.From+1) {
+			return nil, fmt.Errorf("verses %q not found", r.String())
+		}
+
+		surahs = append(surahs, surah)
+	}
+
+	return
 }
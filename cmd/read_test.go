@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/vanillaiice/quran-cli/db"
+	"github.com/vanillaiice/quran-cli/ref"
+)
+
+// quranJSON returns quran-json data holding every verse,
+// translated in english unless lang is the arabic language.
+func quranJSON(t *testing.T, lang string) string {
+	t.Helper()
+
+	surahs := make([]db.Surah, ref.MaxSurahId)
+
+	for i := range surahs {
+		s := &surahs[i]
+		s.Id, s.TotalVerses, s.Type = i+1, db.TotalVerses(i+1), "meccan"
+		s.Name, s.Transliteration = fmt.Sprintf("سورة %d", s.Id), fmt.Sprintf("Surah-%d", s.Id)
+
+		if lang != db.Arabic {
+			s.Translation = fmt.Sprintf("Surah %d", s.Id)
+		}
+
+		for v := 1; v <= s.TotalVerses; v++ {
+			verse := db.Verse{Id: v, Text: fmt.Sprintf("آية %d:%d", s.Id, v)}
+			if lang != db.Arabic {
+				verse.Translation = fmt.Sprintf("verse %d:%d", s.Id, v)
+			}
+			s.Verses = append(s.Verses, verse)
+		}
+	}
+
+	data, err := json.Marshal(surahs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(data)
+}
+
+// memory returns an in-memory store holding the arabic text and an english
+// translation of every verse, the words of the first surah and a tafsir.
+func memory(t *testing.T) *db.Memory {
+	t.Helper()
+
+	m := db.NewMemory()
+
+	for _, lang := range []string{db.Arabic, "en"} {
+		if err := m.InitFromReader(strings.NewReader(quranJSON(t, lang)), lang, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var words strings.Builder
+	for v := 1; v <= db.TotalVerses(1); v++ {
+		for p := 1; p <= 2; p++ {
+			fmt.Fprintf(&words, "1:%d:%d\tكلمة\tkalima\tword %d\n", v, p, p)
+		}
+	}
+
+	if err := m.ImportWords(strings.NewReader(words.String()), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	tafsir := `{"name": "test", "language": "en", "commentaries": [
+		{"verses": "2:1-5", "text": "on the first verses"},
+		{"verses": "2:3", "text": "on the third verse"}
+	]}`
+
+	if err := m.ImportTafsirJSON(strings.NewReader(tafsir), db.Tafsir{}); err != nil {
+		t.Fatal(err)
+	}
+
+	return m
+}
+
+// verses returns the references of the verses of the surahs.
+func verses(surahs []*db.Surah) (refs []string) {
+	for _, s := range surahs {
+		for _, v := range s.Verses {
+			refs = append(refs, fmt.Sprintf("%d:%d", s.Id, v.Id))
+		}
+	}
+	return
+}
+
+func TestReadSurahs(t *testing.T) {
+	m := memory(t)
+
+	tests := []struct {
+		name  string
+		opts  readOptions
+		first string
+		last  string
+		count int
+		start int
+		err   bool
+	}{
+		{"number", readOptions{number: 112}, "112:1", "112:4", 4, 0, false},
+		{"number not found", readOptions{number: 115}, "", "", 0, 0, true},
+		{"name", readOptions{surah: "surah 112"}, "112:1", "112:4", 4, 0, false},
+		{"exact name", readOptions{surah: "Surah-112", exact: true}, "112:1", "112:4", 4, 0, false},
+		{"exact name not found", readOptions{surah: "surah 112", exact: true}, "", "", 0, 0, true},
+		{"refs", readOptions{refs: "2:255,112"}, "2:255", "112:4", 5, 0, false},
+		{"refs not found", readOptions{refs: "1:8"}, "", "", 0, 0, true},
+		{"juz", readOptions{division: db.Juz, part: 30, number: 1}, "78:1", "114:6", 564, 0, false},
+		{"hizb quarter", readOptions{division: db.HizbQuarter, part: 240}, "100:9", "114:6", 82, 0, false},
+		{"juz out of range", readOptions{division: db.Juz, part: 31}, "", "", 0, 0, true},
+		{"start", readOptions{startSurah: 36, startVerse: 10, number: 1}, "36:1", "36:83", 83, 9, false},
+		{"start not found", readOptions{startSurah: 115, startVerse: 1}, "", "", 0, 0, true},
+		{"nothing", readOptions{}, "", "", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			surahs, start, err := readSurahs(m, "en", tt.opts)
+
+			if tt.err {
+				if err == nil {
+					t.Fatalf("readSurahs() = %v, want an error", verses(surahs))
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			refs := verses(surahs)
+
+			if len(refs) != tt.count || refs[0] != tt.first || refs[len(refs)-1] != tt.last {
+				t.Fatalf("readSurahs() = %d verses from %s to %s, want %d from %s to %s", len(refs), refs[0], refs[len(refs)-1], tt.count, tt.first, tt.last)
+			}
+
+			if start != tt.start {
+				t.Errorf("start = %d, want %d", start, tt.start)
+			}
+
+			if got := surahs[0].Verses[0].Translation; got != "verse "+tt.first {
+				t.Errorf("translation of %s = %q, want the english one", tt.first, got)
+			}
+		})
+	}
+}
+
+func TestReadRandomSurah(t *testing.T) {
+	surahs, _, err := readSurahs(memory(t), "en", readOptions{random: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(surahs) != 1 || len(surahs[0].Verses) != db.TotalVerses(surahs[0].Id) {
+		t.Errorf("readSurahs() = %v, want a whole surah", verses(surahs))
+	}
+}
+
+func TestLoadWords(t *testing.T) {
+	m := memory(t)
+
+	surahs, _, err := readSurahs(m, "en", readOptions{refs: "1,2:1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = loadWords(m, surahs); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, v := range surahs[0].Verses {
+		if len(v.Words) != 2 || v.Words[0].Position != 1 || v.Words[1].Gloss != "word 2" {
+			t.Errorf("words of 1:%d = %v, want its two words in order", v.Id, v.Words)
+		}
+	}
+
+	if words := surahs[1].Verses[0].Words; len(words) != 0 {
+		t.Errorf("words of 2:1 = %v, want none", words)
+	}
+
+	if err = loadWords(db.NewMemory(), surahs); err == nil {
+		t.Error("loadWords() error = nil, want an error without word-by-word data")
+	}
+}
+
+func TestTafsirFunc(t *testing.T) {
+	m := memory(t)
+
+	t.Run("choose", func(t *testing.T) {
+		tafsir, err := chooseTafsir(m, "", "fr")
+		if err != nil || tafsir.Name != "test" || tafsir.Title != "test" {
+			t.Fatalf("chooseTafsir() = %v, %v, want the only tafsir", tafsir, err)
+		}
+	})
+
+	tests := []struct {
+		verse int
+		want  string
+	}{
+		{1, "2:1-5\non the first verses"},
+		{3, "2:1-5\non the first verses\n\n2:3\non the third verse"},
+		{6, ""},
+	}
+
+	commentary := tafsirFunc(m, "test")
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("2:%d", tt.verse), func(t *testing.T) {
+			got, err := commentary(2, tt.verse)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tt.want {
+				t.Errorf("commentary = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/charmbracelet/log"
+	"github.com/urfave/cli/v2"
+	"github.com/vanillaiice/quran-cli/db"
+)
+
+// removeCmd is the remove command.
+// It deletes the data of installed languages.
+var removeCmd = &cli.Command{
+	Name:    "remove",
+	Aliases: []string{"rm"},
+	Usage:   "remove the data of languages",
+	Flags: []cli.Flag{
+		dataPathFlag(),
+		&cli.StringFlag{
+			Name:     "language",
+			Aliases:  []string{"l"},
+			Usage:    "remove language `LANGUAGE`, or a comma separated list of languages",
+			Required: true,
+		},
+		&cli.BoolFlag{
+			Name:    "force",
+			Aliases: []string{"f"},
+			Usage:   "remove the arabic text even if translations are left without it",
+		},
+	},
+	Action: func(ctx *cli.Context) (err error) {
+		langs := splitLanguages(ctx.String("language"))
+		if len(langs) == 0 {
+			return fmt.Errorf("please specify a language")
+		}
+
+		dataPath, err := getDataPath(ctx.String("data-path"))
+		if err != nil {
+			return
+		}
+
+		d, err := openDb(dataPath)
+		if err != nil {
+			return
+		}
+		defer d.Close()
+
+		for _, lang := range langs {
+			var ok bool
+
+			if ok, err = d.HasLanguage(lang); err != nil {
+				return
+			}
+
+			if !ok {
+				return fmt.Errorf("data for language %s not found", lang)
+			}
+		}
+
+		if slices.Contains(langs, db.Arabic) && !ctx.Bool("force") {
+			var installed []string
+
+			if installed, err = d.Languages(); err != nil {
+				return
+			}
+
+			for _, lang := range installed {
+				if !slices.Contains(langs, lang) {
+					return fmt.Errorf("the translations rely on the arabic text, use --force to remove it anyway")
+				}
+			}
+		}
+
+		// the arabic text is removed last, the translations relying on it.
+		slices.SortStableFunc(langs, func(a, b string) int {
+			switch {
+			case a == db.Arabic:
+				return 1
+			case b == db.Arabic:
+				return -1
+			default:
+				return 0
+			}
+		})
+
+		for _, lang := range langs {
+			if err = d.RemoveLanguage(lang); err != nil {
+				return
+			}
+
+			log.Infof("removed language %s", lang)
+		}
+
+		return d.Vacuum()
+	},
+}
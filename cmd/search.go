@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/muesli/termenv"
+	"github.com/urfave/cli/v2"
+	"github.com/vanillaiice/quran-cli/arabic"
+	"github.com/vanillaiice/quran-cli/db"
+)
+
+// searchCmd is the search command.
+// It finds verses containing the given words.
+var searchCmd = &cli.Command{
+	Name:      "search",
+	Aliases:   []string{"s"},
+	Usage:     "search verses by words in the arabic text or translation",
+	ArgsUsage: "WORDS...",
+	Flags: []cli.Flag{
+		&cli.PathFlag{
+			Name:    "data-path",
+			Aliases: []string{"p"},
+			Usage:   "data path `PATH`",
+			Value:   "",
+		},
+		&cli.StringFlag{
+			Name:    "language",
+			Aliases: []string{"l"},
+			Usage:   "search in `LANGUAGE`",
+			Value:   "en",
+		},
+		&cli.IntFlag{
+			Name:    "limit",
+			Aliases: []string{"n"},
+			Usage:   "show at most `LIMIT` results (0 for all)",
+			Value:   20,
+		},
+		&cli.BoolFlag{
+			Name:  "sajdah",
+			Usage: "list the verses of prostration",
+		},
+		&cli.BoolFlag{
+			Name:  "root",
+			Usage: "search the words derived from the root given by the letters of the arguments",
+		},
+	},
+	Action: func(ctx *cli.Context) (err error) {
+		query := strings.Join(ctx.Args().Slice(), " ")
+		if strings.TrimSpace(query) == "" && !ctx.Bool("sajdah") {
+			return fmt.Errorf("please specify words to search for")
+		}
+
+		dataPath, err := getDataPath(ctx.String("data-path"))
+		if err != nil {
+			return
+		}
+
+		d, err := openDb(dataPath)
+		if err != nil {
+			return
+		}
+		defer d.Close()
+
+		lang := ctx.String("language")
+
+		ok, err := d.HasLanguage(lang)
+		if err != nil {
+			return
+		}
+
+		if !ok {
+			return fmt.Errorf("data for language %s not found, initialize it with the init command", lang)
+		}
+
+		output := termenv.NewOutput(os.Stdout)
+
+		if ctx.Bool("sajdah") {
+			return listSajdahs(output, d, lang)
+		}
+
+		if ctx.Bool("root") {
+			return searchRoot(output, d, query, lang)
+		}
+
+		limit := ctx.Int("limit")
+		if limit <= 0 {
+			limit = -1
+		}
+
+		matches, err := d.Search(query, lang, limit)
+		if err != nil {
+			return
+		}
+
+		if len(matches) == 0 {
+			return fmt.Errorf("no verses found for %q", query)
+		}
+
+		for _, m := range matches {
+			fmt.Printf("%s %s\n", output.String(fmt.Sprintf("%d:%d (%s)", m.SurahId, m.VerseId, m.Transliteration)).Faint(), highlight(output, m.Snippet))
+		}
+
+		return
+	},
+}
+
+// listSajdahs prints the verses of prostration in the language lang.
+func listSajdahs(output *termenv.Output, d db.Store, lang string) error {
+	for _, p := range db.Sajdahs() {
+		surah, err := d.GetVerses(p.Surah, p.Verse, p.Verse, lang)
+		if err != nil {
+			return err
+		}
+
+		if len(surah.Verses) == 0 {
+			return fmt.Errorf("verse %s not found", p)
+		}
+
+		text := surah.Verses[0].Translation
+		if lang == db.Arabic {
+			text = surah.Verses[0].Text
+		}
+
+		fmt.Printf("%s %s %s\n", output.String(fmt.Sprintf("%s (%s)", p, surah.Transliteration)).Faint(), db.SajdahMarker, text)
+	}
+
+	return nil
+}
+
+// searchRoot prints the verses holding words derived from
+// the root of letters letters, grouped by surah.
+func searchRoot(output *termenv.Output, d *db.Conn, letters, lang string) error {
+	root, err := arabic.ParseRoot(letters)
+	if err != nil {
+		return err
+	}
+
+	ok, err := d.HasMorphology()
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return fmt.Errorf("morphological data not found, import it with the import morphology command")
+	}
+
+	matches, err := d.SearchRoot(root, lang)
+	if err != nil {
+		return err
+	}
+
+	if len(matches) == 0 {
+		return fmt.Errorf("no words found for root %s", root)
+	}
+
+	var surahs, words int
+
+	for i, m := range matches {
+		if i == 0 || matches[i-1].SurahId != m.SurahId {
+			var verses, surahWords int
+			for _, n := range matches[i:] {
+				if n.SurahId != m.SurahId {
+					break
+				}
+				verses++
+				surahWords += n.Words
+			}
+
+			fmt.Println(output.String(fmt.Sprintf("#%d %s: %d verses, %d words", m.SurahId, m.Transliteration, verses, surahWords)).Bold())
+			surahs++
+		}
+
+		words += m.Words
+
+		fmt.Printf("%s %s\n", output.String(fmt.Sprintf("%d:%d", m.SurahId, m.VerseId)).Faint(), m.Text)
+	}
+
+	fmt.Printf("\nroot %s: %d words in %d verses of %d surahs\n", root, words, len(matches), surahs)
+
+	return nil
+}
+
+// highlight styles the matched terms of a snippet.
+func highlight(output *termenv.Output, snippet string) string {
+	var b strings.Builder
+
+	for {
+		start := strings.Index(snippet, db.SnippetStart)
+		if start == -1 {
+			break
+		}
+
+		end := strings.Index(snippet[start:], db.SnippetEnd)
+		if end == -1 {
+			break
+		}
+		end += start
+
+		b.WriteString(snippet[:start])
+		b.WriteString(output.String(snippet[start+len(db.SnippetStart) : end]).Bold().Underline().String())
+
+		snippet = snippet[end+len(db.SnippetEnd):]
+	}
+
+	b.WriteString(snippet)
+
+	return b.String()
+}
@@ -1,36 +1,31 @@
 package cmd
 
-// langCode is the code of the language
-type langCode string
+import (
+	"path/filepath"
+	"slices"
+	"strings"
 
-// enum of languages.
-const (
-	Arabic          langCode = "ar"
-	Bengali         langCode = "bn"
-	Chinese         langCode = "zh"
-	English         langCode = "en"
-	Spanish         langCode = "es"
-	French          langCode = "fr"
-	Indonesian      langCode = "id"
-	Russian         langCode = "ru"
-	Swedish         langCode = "sv"
-	Turkish         langCode = "tr"
-	Urdu            langCode = "ur"
-	Transliteration langCode = "transliteration"
+	"github.com/vanillaiice/quran-cli/source"
 )
 
-// sources is a map of languages and their corresponding sources.
-var sources = map[langCode]string{
-	Arabic:          "https://cdn.jsdelivr.net/npm/quran-json@3.1.2/dist/quran.json",
-	Bengali:         "https://cdn.jsdelivr.net/npm/quran-json@3.1.2/dist/quran_bn.json",
-	Chinese:         "https://cdn.jsdelivr.net/npm/quran-json@3.1.2/dist/quran_zh.json",
-	English:         "https://cdn.jsdelivr.net/npm/quran-json@3.1.2/dist/quran_en.json",
-	Spanish:         "https://cdn.jsdelivr.net/npm/quran-json@3.1.2/dist/quran_es.json",
-	French:          "https://cdn.jsdelivr.net/npm/quran-json@3.1.2/dist/quran_fr.json",
-	Indonesian:      "https://cdn.jsdelivr.net/npm/quran-json@3.1.2/dist/quran_id.json",
-	Russian:         "https://cdn.jsdelivr.net/npm/quran-json@3.1.2/dist/quran_ru.json",
-	Swedish:         "https://cdn.jsdelivr.net/npm/quran-json@3.1.2/dist/quran_sv.json",
-	Turkish:         "https://cdn.jsdelivr.net/npm/quran-json@3.1.2/dist/quran_tr.json",
-	Urdu:            "https://cdn.jsdelivr.net/npm/quran-json@3.1.2/dist/quran_ur.json",
-	Transliteration: "https://cdn.jsdelivr.net/npm/quran-json@3.1.2/dist/quran_transliteration.json",
+// defaultLanguage is the code of the language read by default.
+const defaultLanguage = "en"
+
+// sourcesFile is the name of the file of the user defined sources, in the data path.
+const sourcesFile = "sources.json"
+
+// loadSources returns the registry of the built-in
+// sources and of the user defined ones of dataPath.
+func loadSources(dataPath string) (*source.Registry, error) {
+	return source.Load(filepath.Join(dataPath, sourcesFile))
+}
+
+// splitLanguages returns the codes of the comma separated list of languages s, without duplicates.
+func splitLanguages(s string) (langs []string) {
+	for _, l := range strings.Split(s, ",") {
+		if l = strings.TrimSpace(l); l != "" && !slices.Contains(langs, l) {
+			langs = append(langs, l)
+		}
+	}
+	return
 }
@@ -0,0 +1,354 @@
+package cmd
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/charmbracelet/log"
+	"github.com/muesli/termenv"
+	"github.com/urfave/cli/v2"
+	"github.com/vanillaiice/quran-cli/db"
+	"github.com/vanillaiice/quran-cli/source"
+	"github.com/vanillaiice/quran-cli/tui"
+)
+
+// tafsirCmd is the tafsir command.
+// It shows the commentary of verses and manages the tafsirs.
+var tafsirCmd = &cli.Command{
+	Name:      "tafsir",
+	Aliases:   []string{"tf"},
+	Usage:     "show the commentary of verses",
+	ArgsUsage: "REFERENCE...",
+	Flags: []cli.Flag{
+		dataPathFlag(),
+		&cli.StringFlag{
+			Name:    "tafsir",
+			Aliases: []string{"t"},
+			Usage:   "show the commentary of tafsir `NAME`",
+		},
+		&cli.StringFlag{
+			Name:    "language",
+			Aliases: []string{"l"},
+			Usage:   "prefer a tafsir in `LANGUAGE` if none is given",
+			Value:   defaultLanguage,
+		},
+	},
+	Action: func(ctx *cli.Context) (err error) {
+		if !ctx.Args().Present() {
+			return fmt.Errorf("please specify verses, as in 2:255")
+		}
+
+		dataPath, err := getDataPath(ctx.String("data-path"))
+		if err != nil {
+			return
+		}
+
+		d, err := openDb(dataPath)
+		if err != nil {
+			return
+		}
+		defer d.Close()
+
+		t, err := chooseTafsir(d, ctx.String("tafsir"), ctx.String("language"))
+		if err != nil {
+			return
+		}
+
+		surahs, err := readRefs(d, strings.Join(ctx.Args().Slice(), ","), db.Arabic, false)
+		if err != nil {
+			return
+		}
+
+		output := termenv.NewOutput(os.Stdout)
+
+		// shown holds the commentaries already printed, commenting several verses.
+		shown := make(map[[3]int]bool)
+
+		for _, s := range surahs {
+			for _, v := range s.Verses {
+				commentaries, err := d.GetCommentaries(t.Name, s.Id, v.Id)
+				if err != nil {
+					return err
+				}
+
+				for _, c := range commentaries {
+					if shown[[3]int{c.SurahId, c.From, c.To}] {
+						continue
+					}
+					shown[[3]int{c.SurahId, c.From, c.To}] = true
+
+					if len(shown) > 1 {
+						fmt.Println()
+					}
+
+					fmt.Printf("%s\n%s\n", output.String(fmt.Sprintf("%s (%s)", commentaryRef(c), s.Transliteration)).Faint(), c.Text)
+				}
+			}
+		}
+
+		if len(shown) == 0 {
+			return fmt.Errorf("no commentary found in tafsir %s for %q", t.Name, strings.Join(ctx.Args().Slice(), ","))
+		}
+
+		return
+	},
+	Subcommands: []*cli.Command{
+		{
+			Name:      "import",
+			Aliases:   []string{"im"},
+			Usage:     "import a tafsir, replacing the one of the same name",
+			ArgsUsage: "FILE",
+			Description: "FILE is either a JSON object, with the name, title, author and language of the tafsir\n" +
+				"and its commentaries, or a JSON array of commentaries, each holding the verses it comments,\n" +
+				"as in 2:255 or 2:255-257, and its text. In the CSV format, each record holds the verses and\n" +
+				"the text of a commentary. The format is guessed from the extension of FILE if not given.\n" +
+				"Use - to read from the standard input.",
+			Flags: []cli.Flag{
+				dataPathFlag(),
+				&cli.StringFlag{
+					Name:    "name",
+					Aliases: []string{"n"},
+					Usage:   "store the tafsir under name `NAME`, defaulting to the one of FILE",
+				},
+				&cli.StringFlag{
+					Name:  "title",
+					Usage: "title `TITLE` of the tafsir",
+				},
+				&cli.StringFlag{
+					Name:  "author",
+					Usage: "author `AUTHOR` of the tafsir",
+				},
+				&cli.StringFlag{
+					Name:    "language",
+					Aliases: []string{"l"},
+					Usage:   "language `LANGUAGE` of the tafsir",
+				},
+				&cli.StringFlag{
+					Name:  "format",
+					Usage: "format `FORMAT` of the file (json, csv)",
+				},
+			},
+			Action: func(ctx *cli.Context) (err error) {
+				if ctx.NArg() != 1 {
+					return fmt.Errorf("please specify a file")
+				}
+
+				file := ctx.Args().First()
+
+				t := db.Tafsir{
+					Name:   ctx.String("name"),
+					Title:  ctx.String("title"),
+					Author: ctx.String("author"),
+					Lang:   ctx.String("language"),
+					Source: inputName(file),
+				}
+
+				format := ctx.String("format")
+				if format == "" {
+					format = "json"
+					if strings.EqualFold(filepath.Ext(file), ".csv") {
+						format = "csv"
+					}
+				}
+
+				// a CSV file holds no name, so the tafsir is named after the file.
+				if t.Name == "" && format == "csv" && file != "-" {
+					t.Name = strings.ToLower(strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)))
+				}
+
+				if t.Name != "" && !source.ValidCode(t.Name) {
+					return fmt.Errorf("invalid tafsir name: %q", t.Name)
+				}
+
+				if t.Lang != "" && !source.ValidCode(t.Lang) {
+					return fmt.Errorf("invalid language code: %q", t.Lang)
+				}
+
+				dataPath, err := getDataPath(ctx.String("data-path"))
+				if err != nil {
+					return
+				}
+
+				r, err := openInput(file)
+				if err != nil {
+					return
+				}
+				defer r.Close()
+
+				d, err := openDb(dataPath)
+				if err != nil {
+					return
+				}
+				defer d.Close()
+
+				switch format {
+				case "json":
+					err = d.ImportTafsirJSON(bufio.NewReader(r), t)
+				case "csv":
+					err = d.ImportTafsirCSV(bufio.NewReader(r), t)
+				default:
+					err = fmt.Errorf("unsupported format: %q", format)
+				}
+				if err != nil {
+					return
+				}
+
+				log.Info("imported tafsir")
+
+				return
+			},
+		},
+		{
+			Name:    "list",
+			Aliases: []string{"ls"},
+			Usage:   "list the imported tafsirs",
+			Flags:   []cli.Flag{dataPathFlag()},
+			Action: func(ctx *cli.Context) (err error) {
+				dataPath, err := getDataPath(ctx.String("data-path"))
+				if err != nil {
+					return
+				}
+
+				d, err := openDb(dataPath)
+				if err != nil {
+					return
+				}
+				defer d.Close()
+
+				tafsirs, err := d.Tafsirs()
+				if err != nil {
+					return
+				}
+
+				if len(tafsirs) == 0 {
+					return fmt.Errorf("no tafsir found, import one with the tafsir import command")
+				}
+
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+				fmt.Fprintln(w, "NAME\tTITLE\tAUTHOR\tLANGUAGE\tIMPORTED\tSOURCE")
+
+				for _, t := range tafsirs {
+					imported := "-"
+					if !t.ImportedAt.IsZero() {
+						imported = t.ImportedAt.Local().Format("2006-01-02 15:04")
+					}
+
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", t.Name, t.Title, cmp.Or(t.Author, "-"), cmp.Or(t.Lang, "-"), imported, t.Source)
+				}
+
+				return w.Flush()
+			},
+		},
+		{
+			Name:      "remove",
+			Aliases:   []string{"rm"},
+			Usage:     "remove a tafsir",
+			ArgsUsage: "NAME",
+			Flags:     []cli.Flag{dataPathFlag()},
+			Action: func(ctx *cli.Context) (err error) {
+				if ctx.NArg() != 1 {
+					return fmt.Errorf("please specify a tafsir name")
+				}
+
+				dataPath, err := getDataPath(ctx.String("data-path"))
+				if err != nil {
+					return
+				}
+
+				d, err := openDb(dataPath)
+				if err != nil {
+					return
+				}
+				defer d.Close()
+
+				if err = d.RemoveTafsir(ctx.Args().First()); err != nil {
+					return
+				}
+
+				log.Infof("removed tafsir %s", ctx.Args().First())
+
+				return
+			},
+		},
+	},
+}
+
+// chooseTafsir returns the tafsir name, or if name is empty the first
+// tafsir in the language lang, or else the first tafsir.
+func chooseTafsir(d db.Store, name, lang string) (*db.Tafsir, error) {
+	tafsirs, err := d.Tafsirs()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tafsirs) == 0 {
+		return nil, fmt.Errorf("no tafsir found, import one with the tafsir import command")
+	}
+
+	if name != "" {
+		for _, t := range tafsirs {
+			if t.Name == name {
+				return t, nil
+			}
+		}
+		return nil, fmt.Errorf("tafsir %q not found", name)
+	}
+
+	for _, t := range tafsirs {
+		if t.Lang == lang {
+			return t, nil
+		}
+	}
+
+	return tafsirs[0], nil
+}
+
+// setTafsir sets the function returning the commentary on a verse, of the
+// tafsir name or of the one chosen for language lang, if any is imported.
+func setTafsir(d db.Store, cfg *tui.Config, name, lang string) error {
+	tafsirs, err := d.Tafsirs()
+	if err != nil || (len(tafsirs) == 0 && name == "") {
+		return err
+	}
+
+	t, err := chooseTafsir(d, name, lang)
+	if err != nil {
+		return err
+	}
+
+	cfg.Tafsir = tafsirFunc(d, t.Name)
+
+	return nil
+}
+
+// tafsirFunc returns a function returning the commentaries
+// of the tafsir name on a verse, for the terminal ui.
+func tafsirFunc(d db.Store, name string) func(surahId, verseId int) (string, error) {
+	return func(surahId, verseId int) (string, error) {
+		commentaries, err := d.GetCommentaries(name, surahId, verseId)
+		if err != nil {
+			return "", err
+		}
+
+		texts := make([]string, len(commentaries))
+		for i, c := range commentaries {
+			texts[i] = fmt.Sprintf("%s\n%s", commentaryRef(c), c.Text)
+		}
+
+		return strings.Join(texts, "\n\n"), nil
+	}
+}
+
+// commentaryRef returns the reference to the verses a commentary comments.
+func commentaryRef(c *db.Commentary) string {
+	if c.From == c.To {
+		return fmt.Sprintf("%d:%d", c.SurahId, c.From)
+	}
+	return fmt.Sprintf("%d:%d-%d", c.SurahId, c.From, c.To)
+}
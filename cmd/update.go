@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/charmbracelet/log"
+	"github.com/urfave/cli/v2"
+	"github.com/vanillaiice/quran-cli/db"
+	"github.com/vanillaiice/quran-cli/source"
+)
+
+// updateCmd is the update command.
+// It replaces the data of the installed languages whose source changed.
+var updateCmd = &cli.Command{
+	Name:    "update",
+	Aliases: []string{"up"},
+	Usage:   "update the installed languages whose source changed",
+	Description: "The data is downloaded, then imported in a copy of the database, which replaces\n" +
+		"the database once every language is imported. If anything fails, the database is left unchanged.",
+	Flags: append([]cli.Flag{
+		dataPathFlag(),
+		&cli.StringFlag{
+			Name:    "language",
+			Aliases: []string{"l"},
+			Usage:   "update language `LANGUAGE`, or a comma separated list of languages, instead of every installed one",
+		},
+		&cli.BoolFlag{
+			Name:    "force",
+			Aliases: []string{"f"},
+			Usage:   "update the languages even if their source is unchanged or unknown",
+		},
+		&cli.BoolFlag{
+			Name:    "check",
+			Aliases: []string{"c"},
+			Usage:   "list the languages to update without updating them",
+		},
+	}, downloadFlags()...),
+	Action: func(ctx *cli.Context) (err error) {
+		dataPath, err := getDataPath(ctx.String("data-path"))
+		if err != nil {
+			return
+		}
+
+		sources, err := loadSources(dataPath)
+		if err != nil {
+			return
+		}
+
+		d, err := openDb(dataPath)
+		if err != nil {
+			return
+		}
+
+		infos, err := d.LanguageInfos()
+		d.Close()
+		if err != nil {
+			return
+		}
+
+		var wanted []string
+		if ctx.IsSet("language") {
+			wanted = splitLanguages(ctx.String("language"))
+		}
+
+		for _, lang := range wanted {
+			if !slices.ContainsFunc(infos, func(info *db.LanguageInfo) bool { return info.Lang == lang }) {
+				return fmt.Errorf("data for language %s not found", lang)
+			}
+		}
+
+		var updates []source.Source
+
+		for _, info := range infos {
+			if wanted != nil && !slices.Contains(wanted, info.Lang) {
+				continue
+			}
+
+			src, ok := sources.Get(info.Lang)
+
+			switch {
+			case !ok:
+				log.Warnf("skipping language %s, not in the sources", info.Lang)
+			case ctx.Bool("force"):
+				updates = append(updates, src)
+			case info.Source == src.URL:
+				log.Infof("language %s is up to date", info.Lang)
+			case info.Source == "":
+				log.Warnf("skipping language %s, its source is unknown, use --force to update it", info.Lang)
+			default:
+				log.Infof("language %s: source changed from %s to %s", info.Lang, info.Source, src.URL)
+				updates = append(updates, src)
+			}
+		}
+
+		if len(updates) == 0 {
+			log.Info("nothing to update")
+			return
+		}
+
+		if ctx.Bool("check") {
+			return
+		}
+
+		download, err := newDownloader(ctx)
+		if err != nil {
+			return
+		}
+
+		// the data is downloaded before touching the database.
+		data, err := downloadAll(updates, download)
+		defer closeAll(data)
+		if err != nil {
+			return
+		}
+
+		if err = replaceDb(dataPath, func(d *db.Conn) error {
+			return importAll(d, updates, data, "updating")
+		}); err != nil {
+			return
+		}
+
+		for _, src := range updates {
+			log.Infof("updated language %s", src.Code)
+		}
+
+		return
+	},
+}
+
+// downloadAll downloads the data of the sources, returning
+// readers of the data of each language, to be closed by closeAll.
+func downloadAll(sources []source.Source, download *source.Downloader) (map[string]io.ReadCloser, error) {
+	data := make(map[string]io.ReadCloser, len(sources))
+
+	for _, src := range sources {
+		log.Infof("downloading language %s...", src.Code)
+
+		r, err := openSource(src, initOptions{download: download})()
+		if err != nil {
+			return data, fmt.Errorf("language %s: %w", src.Code, err)
+		}
+
+		data[src.Code] = r
+	}
+
+	return data, nil
+}
+
+// closeAll closes the readers of the data of the languages.
+func closeAll(data map[string]io.ReadCloser) {
+	for _, r := range data {
+		r.Close()
+	}
+}
+
+// arabicFirst compares the language codes a and b, the arabic text coming
+// before the translations and the translations comparing equal.
+func arabicFirst(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == db.Arabic:
+		return -1
+	case b == db.Arabic:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// importAll imports the data of the sources into d, read from data, the
+// arabic text first, the translations relying on it. The progress of the
+// imports is labelled with verb.
+func importAll(d *db.Conn, sources []source.Source, data map[string]io.ReadCloser, verb string) error {
+	sources = slices.Clone(sources)
+
+	slices.SortStableFunc(sources, func(a, b source.Source) int {
+		return arabicFirst(a.Code, b.Code)
+	})
+
+	for _, src := range sources {
+		if err := initLanguage(d, src.Code, src.Format, src.URL, true, func() (io.ReadCloser, error) {
+			return data[src.Code], nil
+		}, progressFunc(fmt.Sprintf("%s %s", verb, src.Code))); err != nil {
+			return fmt.Errorf("language %s: %w", src.Code, err)
+		}
+	}
+
+	return nil
+}
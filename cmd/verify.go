@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/log"
+	"github.com/urfave/cli/v2"
+	"github.com/vanillaiice/quran-cli/dataset"
+	"github.com/vanillaiice/quran-cli/db"
+	"github.com/vanillaiice/quran-cli/source"
+)
+
+// verifyCmd is the verify command.
+// It checks the integrity of the data.
+var verifyCmd = &cli.Command{
+	Name:    "verify",
+	Aliases: []string{"v"},
+	Usage:   "check the integrity of the data",
+	Flags: []cli.Flag{
+		dataPathFlag(),
+		&cli.BoolFlag{
+			Name:    "repair",
+			Aliases: []string{"r"},
+			Usage:   "initialize again the languages having problems",
+		},
+	},
+	Action: func(ctx *cli.Context) (err error) {
+		dataPath, err := getDataPath(ctx.String("data-path"))
+		if err != nil {
+			return
+		}
+
+		problems, err := verify(dataPath)
+		if err != nil {
+			return
+		}
+
+		if len(problems) == 0 {
+			return
+		}
+
+		if !ctx.Bool("repair") {
+			return fmt.Errorf("found %d problems, repair them with verify --repair", len(problems))
+		}
+
+		if err = repair(dataPath, problems); err != nil {
+			return
+		}
+
+		if problems, err = verify(dataPath); err != nil {
+			return
+		}
+
+		if len(problems) != 0 {
+			return fmt.Errorf("found %d problems after repairing", len(problems))
+		}
+
+		return
+	},
+}
+
+// verify prints the integrity problems of the data in dataPath and returns them.
+func verify(dataPath string) (problems []db.Problem, err error) {
+	d, err := openDb(dataPath)
+	if err != nil {
+		return
+	}
+	defer d.Close()
+
+	langs, err := d.Languages()
+	if err != nil {
+		return
+	}
+
+	known, err := knownChecksums(dataPath, langs)
+	if err != nil {
+		return
+	}
+
+	if problems, err = d.Verify(known); err != nil {
+		return
+	}
+
+	for _, lang := range langs {
+		ok := true
+
+		for _, p := range problems {
+			if p.Lang == lang {
+				fmt.Println(p)
+				ok = false
+			}
+		}
+
+		if ok {
+			fmt.Printf("%s: ok\n", lang)
+		}
+	}
+
+	return
+}
+
+// arabicChecksum is the checksum of the canonical arabic text,
+// the arabic text is verified against if not empty.
+var arabicChecksum = db.ArabicChecksum
+
+// knownChecksums returns the checksums of the upstream data of the languages
+// langs: the pinned one of the arabic text, and those of the languages
+// whose data is embedded in the binary.
+func knownChecksums(dataPath string, langs []string) (map[string]string, error) {
+	sources, err := loadSources(dataPath)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]string)
+
+	if arabicChecksum != "" {
+		known[db.Arabic] = arabicChecksum
+	}
+
+	for _, lang := range langs {
+		if _, ok := known[lang]; ok {
+			continue
+		}
+
+		src, ok := sources.Get(lang)
+		if !ok || !embedded(src) {
+			continue
+		}
+
+		r, err := dataset.Open(lang)
+		if err != nil {
+			return nil, err
+		}
+
+		sum, err := db.Checksum(r, lang)
+		r.Close()
+		if err != nil {
+			return nil, fmt.Errorf("embedded data of language %s: %w", lang, err)
+		}
+
+		known[lang] = sum
+	}
+
+	return known, nil
+}
+
+// repair initializes again the languages having problems, starting with
+// the arabic text shared by all of them. The data is downloaded, then
+// imported into a copy of the database swapped in once every import
+// succeeded, so that a failed repair leaves the data unchanged.
+func repair(dataPath string, problems []db.Problem) (err error) {
+	sources, err := loadSources(dataPath)
+	if err != nil {
+		return
+	}
+
+	var repairs []source.Source
+
+	seen := make(map[string]bool)
+	for _, p := range problems {
+		if seen[p.Lang] {
+			continue
+		}
+		seen[p.Lang] = true
+
+		src, ok := sources.Get(p.Lang)
+		if !ok {
+			return fmt.Errorf("language %s is not in the sources, it cannot be repaired", p.Lang)
+		}
+
+		repairs = append(repairs, src)
+	}
+
+	download, err := source.NewDownloader(source.DefaultTimeout, "")
+	if err != nil {
+		return
+	}
+	download.OnRetry = logRetry
+
+	data, err := downloadAll(repairs, download)
+	defer closeAll(data)
+	if err != nil {
+		return
+	}
+
+	if err = replaceDb(dataPath, func(d *db.Conn) error {
+		// the arabic text is deleted first, its problems
+		// possibly not being fixed by importing it again.
+		if seen[db.Arabic] {
+			if err := d.RemoveLanguage(db.Arabic); err != nil {
+				return err
+			}
+		}
+
+		return importAll(d, repairs, data, "repairing")
+	}); err != nil {
+		return
+	}
+
+	for _, src := range repairs {
+		log.Infof("repaired language %s", src.Code)
+	}
+
+	return
+}
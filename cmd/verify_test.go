@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vanillaiice/quran-cli/db"
+)
+
+func TestVerifyArabicChecksum(t *testing.T) {
+	canonical := quranJSON(t, db.Arabic)
+
+	sum, err := db.Checksum(strings.NewReader(canonical), db.Arabic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pinned := arabicChecksum
+	arabicChecksum = sum
+	t.Cleanup(func() { arabicChecksum = pinned })
+
+	tests := []struct {
+		name     string
+		data     string
+		problems int
+	}{
+		{"canonical", canonical, 0},
+		{"one verse changed", strings.Replace(canonical, "آية 2:255", "آية", 1), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dataPath := t.TempDir()
+
+			d, err := openDb(dataPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = d.InitFromReader(strings.NewReader(tt.data), db.Arabic, "quran.json", nil)
+			d.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			problems, err := verify(dataPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(problems) != tt.problems {
+				t.Errorf("verify() = %v, want %d problems", problems, tt.problems)
+			}
+		})
+	}
+}
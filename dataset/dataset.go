@@ -0,0 +1,98 @@
+// Package dataset holds the quran-json data embedded in the binary,
+// to initialize the database without network.
+//
+// The arabic text, in data/arabic, is embedded in every build, and the
+// translations, in data/translations, with the offline_all build tag only.
+// Both are downloaded with go generate, which needs network.
+package dataset
+
+//go:generate go run ./gen -arabic data/arabic -translations data/translations -sums ../source/sums.go -checksum ../db/checksum.go
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Arabic is the code of the arabic language.
+const Arabic = "ar"
+
+// ErrNotEmbedded is returned when the data of a language is not embedded.
+var ErrNotEmbedded = errors.New("data not embedded")
+
+// Has returns true if the data of the language lang is embedded.
+func Has(lang string) bool {
+	_, err := fs.Stat(files, name(lang))
+	return err == nil
+}
+
+// Languages returns the codes of the embedded languages.
+func Languages() (langs []string) {
+	for _, dir := range []string{"data/arabic", "data/translations"} {
+		entries, err := fs.ReadDir(files, dir)
+		if err != nil {
+			continue
+		}
+
+		for _, e := range entries {
+			if lang, ok := strings.CutSuffix(e.Name(), ".json.gz"); ok {
+				langs = append(langs, lang)
+			}
+		}
+	}
+
+	sort.Strings(langs)
+
+	return
+}
+
+// Open returns a reader of the embedded quran-json data of the language lang.
+func Open(lang string) (io.ReadCloser, error) {
+	if !Has(lang) {
+		if lang == Arabic {
+			return nil, fmt.Errorf("%w, download it with go generate ./dataset before building quran-cli", ErrNotEmbedded)
+		}
+		return nil, fmt.Errorf("%w, download it with go generate ./dataset and build quran-cli with the offline_all tag", ErrNotEmbedded)
+	}
+
+	f, err := files.Open(name(lang))
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &reader{Reader: r, file: f}, nil
+}
+
+// name returns the name of the embedded file of the language lang.
+func name(lang string) string {
+	if lang == Arabic {
+		return path.Join("data/arabic", lang+".json.gz")
+	}
+	return path.Join("data/translations", lang+".json.gz")
+}
+
+// reader reads a compressed embedded file.
+type reader struct {
+	*gzip.Reader
+	file fs.File
+}
+
+// Close closes the reader and the embedded file.
+func (r *reader) Close() error {
+	err := r.Reader.Close()
+	if fileErr := r.file.Close(); err == nil {
+		err = fileErr
+	}
+	return err
+}
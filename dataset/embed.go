@@ -0,0 +1,14 @@
+//go:build !offline_all
+
+package dataset
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed all:data/arabic
+var embedded embed.FS
+
+// files holds the arabic text.
+var files fs.FS = embedded
@@ -0,0 +1,14 @@
+//go:build offline_all
+
+package dataset
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed all:data/arabic all:data/translations
+var embedded embed.FS
+
+// files holds the arabic text and the translations.
+var files fs.FS = embedded
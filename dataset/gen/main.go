@@ -0,0 +1,162 @@
+// Command gen downloads the quran-json data embedded by the dataset
+// package, and writes the hashes pinned by the built-in sources and
+// the checksum of the arabic text pinned by the db package.
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/vanillaiice/quran-cli/db"
+	"github.com/vanillaiice/quran-cli/source"
+)
+
+func main() {
+	arabic := flag.String("arabic", "", "write the arabic text in directory `DIR`")
+	translations := flag.String("translations", "", "write the translations in directory `DIR`")
+	sumsFile := flag.String("sums", "", "write the hashes of the data of the built-in sources in the go file `FILE`")
+	checksumFile := flag.String("checksum", "", "write the checksum of the arabic text in the go file `FILE`")
+	flag.Parse()
+
+	if *arabic == "" && *translations == "" && *sumsFile == "" && *checksumFile == "" {
+		log.Fatal("please specify the -arabic, -translations, -sums or -checksum output")
+	}
+
+	download, err := source.NewDownloader(source.DefaultTimeout, "")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	sums := make(map[string]string)
+
+	for _, src := range source.Builtin().Sources() {
+		out := *translations
+		if src.Code == "ar" {
+			out = *arabic
+		}
+
+		if out == "" && *sumsFile == "" && (src.Code != "ar" || *checksumFile == "") {
+			continue
+		}
+
+		data, err := fetch(download, src)
+		if err != nil {
+			log.Fatalf("%s: %v", src.Code, err)
+		}
+
+		h := sha256.Sum256(data)
+		sums[src.Code] = hex.EncodeToString(h[:])
+
+		if out != "" {
+			if err = save(data, filepath.Join(out, src.Code+".json.gz")); err != nil {
+				log.Fatalf("%s: %v", src.Code, err)
+			}
+		}
+
+		if src.Code == "ar" && *checksumFile != "" {
+			if err = writeChecksum(data, *checksumFile); err != nil {
+				log.Fatalf("%s: %v", src.Code, err)
+			}
+		}
+
+		log.Printf("downloaded %s, sha256 %s", src.Code, sums[src.Code])
+	}
+
+	if *sumsFile != "" {
+		if err = writeSums(sums, *sumsFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// fetch downloads the data of the source src, verified against its pinned hash if any.
+func fetch(download *source.Downloader, src source.Source) ([]byte, error) {
+	r, err := download.Open(src)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+// save writes data compressed to file.
+func save(data []byte, file string) (err error) {
+	if err = os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	w, err := gzip.NewWriterLevel(f, gzip.BestCompression)
+	if err != nil {
+		return
+	}
+
+	if _, err = w.Write(data); err != nil {
+		return
+	}
+
+	return w.Close()
+}
+
+// writeSums writes the go file holding the hashes sums of the data of the built-in sources.
+func writeSums(sums map[string]string, file string) error {
+	var b bytes.Buffer
+
+	fmt.Fprintln(&b, "// Code generated by go run ./dataset/gen -sums; DO NOT EDIT.")
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, "package source")
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, "// sums are the SHA-256 hashes of the quran-json data of the built-in sources, by language code.")
+	fmt.Fprintln(&b, "var sums = map[string]string{")
+	for _, src := range source.Builtin().Sources() {
+		if sum, ok := sums[src.Code]; ok {
+			fmt.Fprintf(&b, "%q: %q,\n", src.Code, sum)
+		}
+	}
+	fmt.Fprintln(&b, "}")
+
+	data, err := format.Source(b.Bytes())
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(file, data, 0644)
+}
+
+// writeChecksum writes the go file holding the checksum of the arabic text of the quran-json data.
+func writeChecksum(data []byte, file string) error {
+	sum, err := db.Checksum(bytes.NewReader(data), db.Arabic)
+	if err != nil {
+		return err
+	}
+
+	var b bytes.Buffer
+
+	fmt.Fprintln(&b, "// Code generated by go run ./dataset/gen -checksum; DO NOT EDIT.")
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, "package db")
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, "// ArabicChecksum is the checksum of the canonical arabic text, as returned by Checksum for its quran-json data.")
+	fmt.Fprintf(&b, "const ArabicChecksum = %q\n", sum)
+
+	return os.WriteFile(file, b.Bytes(), 0644)
+}
@@ -1,15 +1,23 @@
 package db
 
 import (
+	"bufio"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 
+	"github.com/vanillaiice/quran-cli/arabic"
+	"github.com/vanillaiice/quran-cli/migrate"
 	_ "modernc.org/sqlite"
 )
 
+// Arabic is the code of the arabic language, whose text
+// is stored once for all the translations.
+const Arabic = "ar"
+
 type Surah struct {
 	Id              int     `json:"id"`
 	Name            string  `json:"name"`
@@ -24,6 +32,8 @@ type Verse struct {
 	Id          int    `json:"id"`
 	Text        string `json:"text"`
 	Translation string `json:"translation"`
+	// Words are the words of the verse, only loaded for interlinear reading.
+	Words []Word `json:"-"`
 }
 
 type Conn struct {
@@ -36,31 +46,12 @@ func New(path string) (*Conn, error) {
 		return nil, err
 	}
 
-	stmt := `
-		CREATE TABLE IF NOT EXISTS Quran(
-			surah_id INTEGER PRIMARY KEY,
-			name TEXT NOT NULL,
-			transliteration TEXT NOT NULL,
-			translation TEXT NOT NULL,
-			type TEXT NOT NULL,
-			total_verses INTEGER NOT NULL
-		);
-
-		CREATE TABLE IF NOT EXISTS Verses(
-			id INTEGER PRIMARY KEY,
-			surah_id INTEGER NOT NULL,
-			verse_id INTEGER NOT NULL,
-			text TEXT NOT NULL,
-			translation TEXT NOT NULL,
-			FOREIGN KEY (surah_id) REFERENCES Quran(surah_id)
-		);
-	`
-
 	if err = conn.Ping(); err != nil {
 		return nil, err
 	}
 
-	if _, err = conn.Exec(stmt); err != nil {
+	if err = migrate.Run(conn, migrations); err != nil {
+		conn.Close()
 		return nil, err
 	}
 
@@ -71,55 +62,317 @@ func (c *Conn) Close() error {
 	return c.db.Close()
 }
 
-func (c *Conn) InitFromReader(r io.Reader) error {
-	var s []*Surah
-	if err := json.NewDecoder(r).Decode(&s); err != nil {
+// Progress is called during imports with the number
+// of imported verses and the total number of verses.
+type Progress func(done, total int)
+
+// InitFromReader imports the quran-json data read from r, storing its
+// translation under the language lang and recording source as the location
+// it was imported from. The surahs are decoded one at a time and imported
+// in a single transaction. If progress is not nil, it is called after each
+// imported surah.
+func (c *Conn) InitFromReader(r io.Reader, lang, source string, progress Progress) error {
+	next, err := decodeSurahs(r)
+	if err != nil {
 		return err
 	}
-	return initDb(s, c)
+	return initDb(next, lang, source, progress, c)
 }
 
-func (c *Conn) InitFromFile(file string) error {
-	var surahs []*Surah
+// decodeSurahs returns a function decoding the surahs
+// of quran-json data one at a time, until io.EOF, checking
+// that the data holds every verse of every surah in order.
+func decodeSurahs(r io.Reader) (next func() (*Surah, error), err error) {
+	dec := json.NewDecoder(r)
 
-	f, err := os.ReadFile(file)
+	t, err := dec.Token()
 	if err != nil {
-		return err
+		return
+	}
+
+	if t != json.Delim('[') {
+		return nil, fmt.Errorf("invalid quran-json data: expected an array of surahs")
+	}
+
+	// id of the last decoded surah.
+	var id int
+
+	next = func() (*Surah, error) {
+		if !dec.More() {
+			if id != len(verseCounts) {
+				return nil, fmt.Errorf("invalid quran-json data: expected %d surahs, got %d", len(verseCounts), id)
+			}
+			return nil, io.EOF
+		}
+
+		var s Surah
+		if err := dec.Decode(&s); err != nil {
+			return nil, err
+		}
+
+		id++
+
+		if err := validateSurah(&s, id); err != nil {
+			return nil, fmt.Errorf("invalid quran-json data: %w", err)
+		}
+
+		return &s, nil
+	}
+
+	return
+}
+
+// validateSurah checks that s is the surah number id, holding all of its verses in order.
+func validateSurah(s *Surah, id int) error {
+	if s.Id != id {
+		return fmt.Errorf("expected surah #%d, got #%d", id, s.Id)
 	}
 
-	if err := json.Unmarshal(f, &surahs); err != nil {
+	if total := TotalVerses(id); len(s.Verses) != total || s.TotalVerses != total {
+		return fmt.Errorf("surah #%d: expected %d verses, got %d", id, total, len(s.Verses))
+	}
+
+	for i, v := range s.Verses {
+		if v.Id != i+1 {
+			return fmt.Errorf("surah #%d: expected verse %d, got %d", id, i+1, v.Id)
+		}
+
+		if v.Text == "" {
+			return fmt.Errorf("verse %d:%d: missing arabic text", id, v.Id)
+		}
+	}
+
+	return nil
+}
+
+// InitFromFile imports the quran-json data of file,
+// storing its translation under the language lang.
+func (c *Conn) InitFromFile(file, lang string, progress Progress) error {
+	f, err := os.Open(file)
+	if err != nil {
 		return err
 	}
+	defer f.Close()
+
+	return c.InitFromReader(bufio.NewReader(f), lang, file, progress)
+}
+
+// ImportLegacy imports a database in the former layout,
+// holding the arabic text and the translation in language
+// lang in a single table, into the database.
+func (c *Conn) ImportLegacy(path, lang string) (err error) {
+	ctx := context.Background()
+
+	// attached databases are only visible to the connection attaching them.
+	conn, err := c.db.Conn(ctx)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	if _, err = conn.ExecContext(ctx, `ATTACH DATABASE ? AS legacy`, path); err != nil {
+		return
+	}
+	defer func() {
+		if _, detachErr := conn.ExecContext(ctx, `DETACH DATABASE legacy`); err == nil {
+			err = detachErr
+		}
+	}()
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	stmts := []string{
+		`INSERT INTO Quran
+			SELECT surah_id, name, transliteration, type, total_verses FROM legacy.Quran WHERE true
+			ON CONFLICT(surah_id) DO NOTHING`,
+		`INSERT INTO Verses (id, surah_id, verse_id, text)
+			SELECT id, surah_id, verse_id, text FROM legacy.Verses WHERE true
+			ON CONFLICT(id) DO NOTHING`,
+		`INSERT INTO SurahTranslations
+			SELECT ?1, surah_id, translation FROM legacy.Quran WHERE translation != '' AND ?1 != ?2
+			ON CONFLICT(lang, surah_id) DO UPDATE SET translation = excluded.translation`,
+		`INSERT INTO Translations
+			SELECT ?1, surah_id, verse_id, translation FROM legacy.Verses WHERE translation != '' AND ?1 != ?2
+			ON CONFLICT(lang, surah_id, verse_id) DO UPDATE SET text = excluded.text`,
+	}
+
+	for _, stmt := range stmts {
+		if _, err = tx.ExecContext(ctx, stmt, lang, Arabic); err != nil {
+			return
+		}
+	}
+
+	if err = normalizeVerses(tx); err != nil {
+		return
+	}
+
+	if err = recordChecksum(tx, Arabic); err != nil {
+		return
+	}
+
+	if lang != Arabic {
+		if err = recordChecksum(tx, lang); err != nil {
+			return
+		}
+	}
+
+	if err = recordImport(tx, lang, path, lang != Arabic); err != nil {
+		return
+	}
+
+	return tx.Commit()
+}
+
+// HasLanguage returns true if the data of the language lang is in the database.
+func (c *Conn) HasLanguage(lang string) (ok bool, err error) {
+	if lang == Arabic {
+		err = c.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM Verses)`).Scan(&ok)
+	} else {
+		err = c.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM Translations WHERE lang = ?)`, lang).Scan(&ok)
+	}
+	return
+}
+
+// Languages returns the codes of the languages in the database.
+func (c *Conn) Languages() ([]string, error) {
+	return languages(c.db)
+}
+
+// RemoveLanguage deletes the translation in the language lang.
+// Removing the arabic language deletes the surahs and their text,
+// which must be imported again before reading any translation.
+func (c *Conn) RemoveLanguage(lang string) (err error) {
+	tx, err := c.db.Begin()
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	stmts := []string{
+		`DELETE FROM Translations WHERE lang = ?`,
+		`DELETE FROM SurahTranslations WHERE lang = ?`,
+		`DELETE FROM Checksums WHERE lang = ?`,
+		`DELETE FROM Imports WHERE lang = ?`,
+	}
+
+	if lang == Arabic {
+		stmts = append(stmts, `DELETE FROM Verses`, `DELETE FROM Quran`)
+	}
+
+	for _, stmt := range stmts {
+		if _, err = tx.Exec(stmt, lang); err != nil {
+			return
+		}
+	}
+
+	return tx.Commit()
+}
+
+// CopyTo writes a copy of the database to file, which must not exist.
+func (c *Conn) CopyTo(file string) error {
+	_, err := c.db.Exec(`VACUUM INTO ?`, file)
+	return err
+}
+
+// Vacuum rebuilds the database, reclaiming the space of the deleted data.
+func (c *Conn) Vacuum() error {
+	_, err := c.db.Exec(`VACUUM`)
+	return err
+}
+
+// languages returns the codes of the languages in the database.
+func languages(q querier) (langs []string, err error) {
+	var ok bool
+	if err = q.QueryRow(`SELECT EXISTS (SELECT 1 FROM Verses)`).Scan(&ok); err != nil || !ok {
+		return
+	}
+
+	langs = append(langs, Arabic)
 
-	return initDb(surahs, c)
+	rows, err := q.Query(`SELECT DISTINCT lang FROM Translations ORDER BY lang`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var lang string
+		if err = rows.Scan(&lang); err != nil {
+			return nil, err
+		}
+		langs = append(langs, lang)
+	}
+
+	return langs, rows.Err()
+}
+
+// selectSurah selects the verses of surahs, with their translation
+// in the language given as the first two parameters.
+const selectSurah = `
+	SELECT
+		Quran.surah_id,
+		Quran.name,
+		Quran.transliteration,
+		COALESCE(SurahTranslations.translation, ''),
+		Quran.type,
+		Quran.total_verses,
+		Verses.verse_id,
+		Verses.text,
+		COALESCE(Translations.text, '')
+	FROM Quran
+	JOIN Verses
+	ON Verses.surah_id = Quran.surah_id
+	LEFT JOIN SurahTranslations
+	ON SurahTranslations.surah_id = Quran.surah_id
+	AND SurahTranslations.lang = ?
+	LEFT JOIN Translations
+	ON Translations.surah_id = Verses.surah_id
+	AND Translations.verse_id = Verses.verse_id
+	AND Translations.lang = ?`
+
+// GetSurahById returns the surah with id id,
+// with its translation in the language lang.
+func (c *Conn) GetSurahById(id int, lang string) (*Surah, error) {
+	stmt := selectSurah + `
+		WHERE Quran.surah_id = ?
+		ORDER BY Verses.verse_id`
+
+	return c.querySurah(stmt, lang, lang, id)
+}
+
+// GetVerses returns the surah with id surahId, holding only its verses
+// from from to to, inclusive, with their translation in the language lang.
+func (c *Conn) GetVerses(surahId, from, to int, lang string) (*Surah, error) {
+	stmt := selectSurah + `
+		WHERE Quran.surah_id = ?
+		AND Verses.verse_id BETWEEN ? AND ?
+		ORDER BY Verses.verse_id`
+
+	return c.querySurah(stmt, lang, lang, surahId, from, to)
 }
 
-func (c *Conn) GetSurahById(id int) (*Surah, error) {
-	stmt := `
-		SELECT
-			Quran.surah_id,
-			Quran.name,
-			Quran.transliteration,
-			Quran.translation,
-			Quran.type,
-			Quran.total_verses,
-			Verses.verse_id,
-			Verses.text,
-			Verses.translation
-		FROM Quran
-		LEFT JOIN verses
-		ON Verses.surah_id = Quran.surah_id
-		WHERE Quran.surah_id = ?`
-
-	rows, err := c.db.Query(stmt, id)
+// GetSpan returns the surahs holding the verses of the span s, possibly
+// cut at its ends, with their translation in the language lang.
+func (c *Conn) GetSpan(s Span, lang string) ([]*Surah, error) {
+	stmt := selectSurah + `
+		WHERE (Verses.surah_id, Verses.verse_id) >= (?, ?)
+		AND (Verses.surah_id, Verses.verse_id) <= (?, ?)
+		ORDER BY Verses.surah_id, Verses.verse_id`
+
+	rows, err := c.db.Query(stmt, lang, lang, s.From.Surah, s.From.Verse, s.To.Surah, s.To.Verse)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var surah Surah
+	var surahs []*Surah
 
 	for rows.Next() {
+		var surah Surah
 		var v Verse
 
 		if err = rows.Scan(
@@ -129,84 +382,82 @@ func (c *Conn) GetSurahById(id int) (*Surah, error) {
 			&surah.Translation,
 			&surah.Type,
 			&surah.TotalVerses,
-			&v.Id, &v.Text,
+			&v.Id,
+			&v.Text,
 			&v.Translation,
 		); err != nil {
 			return nil, err
 		}
 
-		surah.Verses = append(surah.Verses, v)
+		if len(surahs) == 0 || surahs[len(surahs)-1].Id != surah.Id {
+			surahs = append(surahs, &surah)
+		}
+
+		last := surahs[len(surahs)-1]
+		last.Verses = append(last.Verses, v)
 	}
 
-	return &surah, nil
+	return surahs, rows.Err()
 }
 
-func (c *Conn) GetSurahByName(name string) (*Surah, error) {
-	stmt := `
-		SELECT
-			Quran.surah_id,
-			Quran.name,
-			Quran.transliteration,
-			Quran.translation,
-			Quran.type,
-			Quran.total_verses,
-			Verses.verse_id,
-			Verses.text,
-			Verses.translation
-		FROM Quran
-		LEFT JOIN verses
-		ON Verses.surah_id = Quran.surah_id
-		WHERE Quran.transliteration = ?`
-
-	rows, err := c.db.Query(stmt, name)
+// GetSurahByName returns the surah with the transliterated name name,
+// with its translation in the language lang.
+func (c *Conn) GetSurahByName(name, lang string) (*Surah, error) {
+	stmt := selectSurah + `
+		WHERE Quran.transliteration = ?
+		ORDER BY Verses.verse_id`
+
+	return c.querySurah(stmt, lang, lang, name)
+}
+
+// GetSurahByNameLike returns the first surah whose transliterated name
+// contains name, with its translation in the language lang.
+func (c *Conn) GetSurahByNameLike(name, lang string) (*Surah, error) {
+	stmt := selectSurah + `
+		WHERE Quran.surah_id = (
+			SELECT surah_id FROM Quran
+			WHERE transliteration LIKE ?
+			ORDER BY surah_id
+			LIMIT 1
+		)
+		ORDER BY Verses.verse_id`
+
+	return c.querySurah(stmt, lang, lang, fmt.Sprintf("%%%s%%", name))
+}
+
+// GetTranslations returns the translations of a verse in the languages
+// langs, or in all the languages of the database if none is given.
+func (c *Conn) GetTranslations(surahId, verseId int, langs ...string) (map[string]string, error) {
+	rows, err := c.db.Query(`SELECT lang, text FROM Translations WHERE surah_id = ? AND verse_id = ?`, surahId, verseId)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var surah Surah
+	wanted := make(map[string]bool, len(langs))
+	for _, l := range langs {
+		wanted[l] = true
+	}
 
-	for rows.Next() {
-		var v Verse
+	translations := make(map[string]string)
 
-		if err = rows.Scan(
-			&surah.Id,
-			&surah.Name,
-			&surah.Transliteration,
-			&surah.Translation,
-			&surah.Type,
-			&surah.TotalVerses,
-			&v.Id,
-			&v.Text,
-			&v.Translation,
-		); err != nil {
+	for rows.Next() {
+		var lang, text string
+		if err = rows.Scan(&lang, &text); err != nil {
 			return nil, err
 		}
 
-		surah.Verses = append(surah.Verses, v)
+		if len(langs) == 0 || wanted[lang] {
+			translations[lang] = text
+		}
 	}
 
-	return &surah, nil
+	return translations, rows.Err()
 }
 
-func (c *Conn) GetSurahByNameLike(name string) (*Surah, error) {
-	stmt := `
-		SELECT Quran.surah_id,
-			Quran.name,
-			Quran.transliteration,
-			Quran.translation,
-			Quran.type,
-			Quran.total_verses,
-			Verses.verse_id,
-			Verses.text,
-			Verses.translation
-		FROM Quran
-		LEFT JOIN verses
-		ON Verses.surah_id = Quran.surah_id WHERE Quran.transliteration
-		LIKE ?
-		LIMIT 1`
-
-	rows, err := c.db.Query(stmt, fmt.Sprintf("%%%s%%", name))
+// querySurah runs a query returning a surah with one verse per row.
+func (c *Conn) querySurah(stmt string, args ...any) (*Surah, error) {
+	rows, err := c.db.Query(stmt, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -231,41 +482,124 @@ func (c *Conn) GetSurahByNameLike(name string) (*Surah, error) {
 			return nil, err
 		}
 
-		if v.Id <= len(surah.Verses) {
-			break
-		}
-
 		surah.Verses = append(surah.Verses, v)
 	}
 
-	return &surah, nil
+	return &surah, rows.Err()
 }
 
-func initDb(surahs []*Surah, c *Conn) (err error) {
+// initDb imports the surahs returned by next until it returns io.EOF,
+// storing their translation under the language lang, imported from source.
+// Nothing is imported if any of the surahs fails to be.
+func initDb(next func() (*Surah, error), lang, source string, progress Progress, c *Conn) (err error) {
+	tx, err := c.db.Begin()
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	// the updates are skipped when the text is unchanged,
+	// sparing the rewriting of the full-text indexes.
+	stmts := []string{
+		`INSERT INTO Quran VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(surah_id) DO UPDATE SET
+			name = excluded.name,
+			transliteration = excluded.transliteration,
+			type = excluded.type,
+			total_verses = excluded.total_verses`,
+		`INSERT INTO SurahTranslations VALUES (?, ?, ?)
+		ON CONFLICT(lang, surah_id) DO UPDATE SET translation = excluded.translation`,
+		`INSERT INTO Verses VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET text = excluded.text, normalized = excluded.normalized
+		WHERE text != excluded.text`,
+		`INSERT INTO Translations VALUES (?, ?, ?, ?)
+		ON CONFLICT(lang, surah_id, verse_id) DO UPDATE SET text = excluded.text
+		WHERE text != excluded.text`,
+		`DELETE FROM SurahTranslations WHERE lang = ? AND surah_id = ?`,
+		`DELETE FROM Translations WHERE lang = ? AND surah_id = ? AND verse_id = ?`,
+	}
+
+	prepared := make([]*sql.Stmt, len(stmts))
+
+	for i, stmt := range stmts {
+		if prepared[i], err = tx.Prepare(stmt); err != nil {
+			return
+		}
+		defer prepared[i].Close()
+	}
+
+	insertSurah, insertSurahTranslation, insertVerse, insertTranslation := prepared[0], prepared[1], prepared[2], prepared[3]
+	deleteSurahTranslation, deleteTranslation := prepared[4], prepared[5]
+
 	var verseId int
 
-	for _, s := range surahs {
-		tx, err := c.db.Begin()
-		if err != nil {
+	for {
+		s, err := next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
 			return err
 		}
 
-		if _, err = tx.Exec(`INSERT INTO Quran VALUES (?, ?, ?, ?, ?, ?)`, s.Id, s.Name, s.Transliteration, s.Translation, s.Type, s.TotalVerses); err != nil {
+		if _, err = insertSurah.Exec(s.Id, s.Name, s.Transliteration, s.Type, s.TotalVerses); err != nil {
 			return err
 		}
 
+		// the translations missing from the data are deleted,
+		// replacing those of a former import in the same transaction.
+		if lang != Arabic && s.Translation != "" {
+			if _, err = insertSurahTranslation.Exec(lang, s.Id, s.Translation); err != nil {
+				return err
+			}
+		} else if lang != Arabic {
+			if _, err = deleteSurahTranslation.Exec(lang, s.Id); err != nil {
+				return err
+			}
+		}
+
 		for _, v := range s.Verses {
 			verseId++
 
-			if _, err = tx.Exec(`INSERT INTO Verses VALUES (?, ?, ?, ?, ?)`, verseId, s.Id, v.Id, v.Text, v.Translation); err != nil {
+			if _, err = insertVerse.Exec(verseId, s.Id, v.Id, v.Text, arabic.Normalize(v.Text)); err != nil {
+				return err
+			}
+
+			if lang == Arabic {
+				continue
+			}
+
+			if v.Translation == "" {
+				_, err = deleteTranslation.Exec(lang, s.Id, v.Id)
+			} else {
+				_, err = insertTranslation.Exec(lang, s.Id, v.Id, v.Translation)
+			}
+			if err != nil {
 				return err
 			}
 		}
 
-		if err = tx.Commit(); err != nil {
-			return err
+		if progress != nil {
+			progress(min(verseId, QuranVerses), QuranVerses)
 		}
 	}
 
-	return
+	if verseId == 0 {
+		return fmt.Errorf("no verses to import")
+	}
+
+	if err = recordChecksum(tx, Arabic); err != nil {
+		return
+	}
+
+	if lang != Arabic {
+		if err = recordChecksum(tx, lang); err != nil {
+			return
+		}
+	}
+
+	if err = recordImport(tx, lang, source, lang != Arabic); err != nil {
+		return
+	}
+
+	return tx.Commit()
 }
@@ -0,0 +1,135 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newDb returns a new database in a temporary directory.
+func newDb(t *testing.T) *Conn {
+	t.Helper()
+
+	c, err := New(filepath.Join(t.TempDir(), "quran.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+
+	return c
+}
+
+// quranJSON returns quran-json data holding every verse, whose
+// translation is returned by translation, empty for the arabic text.
+func quranJSON(t *testing.T, translation func(surahId, verseId int) string) string {
+	t.Helper()
+
+	surahs := make([]Surah, len(verseCounts))
+
+	for i := range surahs {
+		s := &surahs[i]
+		s.Id, s.TotalVerses = i+1, verseCounts[i]
+		s.Name, s.Transliteration, s.Type = fmt.Sprintf("سورة %d", s.Id), fmt.Sprintf("Surah-%d", s.Id), "meccan"
+
+		if translation != nil {
+			s.Translation = translation(s.Id, 0)
+		}
+
+		for v := 1; v <= s.TotalVerses; v++ {
+			verse := Verse{Id: v, Text: fmt.Sprintf("بِسْمِ ٱللَّهِ %d:%d", s.Id, v)}
+			if translation != nil {
+				verse.Translation = translation(s.Id, v)
+			}
+			s.Verses = append(s.Verses, verse)
+		}
+	}
+
+	data, err := json.Marshal(surahs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(data)
+}
+
+// initLang imports quran-json data in the language lang.
+func initLang(t *testing.T, c *Conn, lang string, translation func(surahId, verseId int) string) {
+	t.Helper()
+
+	if err := c.InitFromReader(strings.NewReader(quranJSON(t, translation)), lang, "quran_"+lang+".json", nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// countTranslations returns the number of translated verses and surah names in the language lang.
+func countTranslations(t *testing.T, c *Conn, lang string) (verses, surahs int) {
+	t.Helper()
+
+	if err := c.db.QueryRow(`
+		SELECT
+			(SELECT COUNT(*) FROM Translations WHERE lang = ?1),
+			(SELECT COUNT(*) FROM SurahTranslations WHERE lang = ?1)`, lang).Scan(&verses, &surahs); err != nil {
+		t.Fatal(err)
+	}
+
+	return
+}
+
+func TestReimportFailureKeepsData(t *testing.T) {
+	t.Parallel()
+
+	c := newDb(t)
+
+	initLang(t, c, Arabic, nil)
+	initLang(t, c, "en", func(s, v int) string { return fmt.Sprintf("verse %d:%d", s, v) })
+
+	// data truncated after a few surahs.
+	data := quranJSON(t, func(s, v int) string { return "changed" })
+	truncated := data[:len(data)/10]
+
+	if err := c.InitFromReader(strings.NewReader(truncated), "en", "quran_en.json", nil); err == nil {
+		t.Fatal("InitFromReader() error = nil, want an error for truncated data")
+	}
+
+	if verses, surahs := countTranslations(t, c, "en"); verses != QuranVerses || surahs != len(verseCounts) {
+		t.Fatalf("translations = %d verses, %d surahs, want the former import left unchanged", verses, surahs)
+	}
+
+	s, err := c.GetVerses(2, 255, 255, "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := s.Verses[0].Translation; got != "verse 2:255" {
+		t.Errorf("translation of 2:255 = %q, want the former one", got)
+	}
+}
+
+func TestReimportReplacesData(t *testing.T) {
+	t.Parallel()
+
+	c := newDb(t)
+
+	initLang(t, c, Arabic, nil)
+	initLang(t, c, "en", func(s, v int) string { return fmt.Sprintf("verse %d:%d", s, v) })
+
+	// the new data lacks the translation of the first surah.
+	initLang(t, c, "en", func(s, v int) string {
+		if s == 1 {
+			return ""
+		}
+		return "changed"
+	})
+
+	if verses, surahs := countTranslations(t, c, "en"); verses != QuranVerses-TotalVerses(1) || surahs != len(verseCounts)-1 {
+		t.Fatalf("translations = %d verses, %d surahs, want those of the first surah deleted", verses, surahs)
+	}
+
+	if problems, err := c.Verify(nil); err != nil {
+		t.Fatal(err)
+	} else if len(problems) != 1 {
+		t.Errorf("Verify() = %v, want the missing verses reported", problems)
+	}
+}
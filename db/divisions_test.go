@@ -0,0 +1,121 @@
+package db
+
+import "testing"
+
+func TestDivisionSpan(t *testing.T) {
+	tests := []struct {
+		division *Division
+		n        int
+		want     Span
+		err      bool
+	}{
+		{Juz, 1, Span{Position{1, 1}, Position{2, 141}}, false},
+		{Juz, 2, Span{Position{2, 142}, Position{2, 252}}, false},
+		{Juz, 30, Span{Position{78, 1}, Position{114, 6}}, false},
+		{Juz, 0, Span{}, true},
+		{Juz, 31, Span{}, true},
+		{Hizb, 1, Span{Position{1, 1}, Position{2, 74}}, false},
+		{Hizb, 60, Span{Position{87, 1}, Position{114, 6}}, false},
+		{Hizb, 61, Span{}, true},
+		{HizbQuarter, 1, Span{Position{1, 1}, Position{2, 25}}, false},
+		{HizbQuarter, 240, Span{Position{100, 9}, Position{114, 6}}, false},
+		{Manzil, 1, Span{Position{1, 1}, Position{4, 176}}, false},
+		{Manzil, 7, Span{Position{50, 1}, Position{114, 6}}, false},
+		{Page, 1, Span{Position{1, 1}, Position{1, 7}}, false},
+		{Page, 2, Span{Position{2, 1}, Position{2, 5}}, false},
+		{Page, 604, Span{Position{112, 1}, Position{114, 6}}, false},
+		{Page, -1, Span{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.division.Span(tt.n)
+
+		if tt.err {
+			if err == nil {
+				t.Errorf("%s.Span(%d) = %v, want an error", tt.division.Name, tt.n, got)
+			}
+			continue
+		}
+
+		if err != nil || got != tt.want {
+			t.Errorf("%s.Span(%d) = %v, %v, want %v", tt.division.Name, tt.n, got, err, tt.want)
+		}
+	}
+}
+
+func TestDivisionOf(t *testing.T) {
+	tests := []struct {
+		division *Division
+		surah    int
+		verse    int
+		want     int
+	}{
+		{Juz, 1, 1, 1},
+		{Juz, 2, 141, 1},
+		{Juz, 2, 142, 2},
+		{Juz, 77, 50, 29},
+		{Juz, 78, 1, 30},
+		{Juz, 114, 6, 30},
+		{Hizb, 2, 74, 1},
+		{Hizb, 2, 75, 2},
+		{HizbQuarter, 2, 25, 1},
+		{HizbQuarter, 2, 26, 2},
+		{HizbQuarter, 114, 6, 240},
+		{Manzil, 4, 176, 1},
+		{Manzil, 5, 1, 2},
+		{Page, 1, 7, 1},
+		{Page, 2, 1, 2},
+		{Page, 114, 6, 604},
+	}
+
+	for _, tt := range tests {
+		if got := tt.division.Of(tt.surah, tt.verse); got != tt.want {
+			t.Errorf("%s.Of(%d, %d) = %d, want %d", tt.division.Name, tt.surah, tt.verse, got, tt.want)
+		}
+	}
+}
+
+// TestDivisionsCover checks that the parts of each division
+// follow each other and cover every verse of the Quran.
+func TestDivisionsCover(t *testing.T) {
+	for _, d := range []*Division{Juz, Hizb, HizbQuarter, Manzil, Page} {
+		next, verses := Position{1, 1}, 0
+
+		for n := 1; n <= d.Len(); n++ {
+			s, err := d.Span(n)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if s.From != next || less(s.To, s.From) {
+				t.Fatalf("%s %d = %v, want it to start at %v", d.Name, n, s, next)
+			}
+
+			for p := s.From; ; p = (Position{p.Surah, p.Verse + 1}) {
+				if p.Verse > TotalVerses(p.Surah) {
+					p = Position{p.Surah + 1, 1}
+				}
+
+				verses++
+
+				if d.Of(p.Surah, p.Verse) != n {
+					t.Fatalf("%s.Of(%v) = %d, want %d", d.Name, p, d.Of(p.Surah, p.Verse), n)
+				}
+
+				if p == s.To {
+					break
+				}
+			}
+
+			if s.To.Verse == TotalVerses(s.To.Surah) {
+				next = Position{s.To.Surah + 1, 1}
+			} else {
+				next = Position{s.To.Surah, s.To.Verse + 1}
+			}
+		}
+
+		if verses != QuranVerses {
+			t.Errorf("%s covers %d verses, want %d", d.Name, verses, QuranVerses)
+		}
+	}
+}
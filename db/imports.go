@@ -0,0 +1,98 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// timeLayout is the layout of the dates stored by sqlite.
+const timeLayout = "2006-01-02 15:04:05"
+
+// LanguageInfo describes the data of a language in the database.
+type LanguageInfo struct {
+	Lang string
+	// Verses is the number of verses of the arabic text or of the translation.
+	Verses int
+	// Surahs is the number of surahs, or of translated surah names.
+	Surahs int
+	// Size is the size of the text of the verses, in bytes.
+	Size int64
+	// ImportedAt is the date of the last import, zero if unknown.
+	ImportedAt time.Time
+	// Source is the location the data was imported from, empty if unknown.
+	Source string
+}
+
+// LanguageInfos returns the description of the data of the languages in the database.
+func (c *Conn) LanguageInfos() ([]*LanguageInfo, error) {
+	langs, err := languages(c.db)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]*LanguageInfo, 0, len(langs))
+
+	for _, lang := range langs {
+		info := &LanguageInfo{Lang: lang}
+
+		if lang == Arabic {
+			err = c.db.QueryRow(`
+				SELECT
+					(SELECT COUNT(*) FROM Verses),
+					(SELECT COUNT(*) FROM Quran),
+					(SELECT COALESCE(SUM(LENGTH(CAST(text AS BLOB))), 0) FROM Verses)`,
+			).Scan(&info.Verses, &info.Surahs, &info.Size)
+		} else {
+			err = c.db.QueryRow(`
+				SELECT
+					(SELECT COUNT(*) FROM Translations WHERE lang = ?1),
+					(SELECT COUNT(*) FROM SurahTranslations WHERE lang = ?1),
+					(SELECT COALESCE(SUM(LENGTH(CAST(text AS BLOB))), 0) FROM Translations WHERE lang = ?1)`,
+				lang,
+			).Scan(&info.Verses, &info.Surahs, &info.Size)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var importedAt sql.NullString
+
+		err = c.db.QueryRow(`SELECT source, imported_at FROM Imports WHERE lang = ?`, lang).Scan(&info.Source, &importedAt)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+
+		if importedAt.Valid {
+			if info.ImportedAt, err = time.ParseInLocation(timeLayout, importedAt.String, time.UTC); err != nil {
+				return nil, err
+			}
+		}
+
+		infos = append(infos, info)
+	}
+
+	return infos, nil
+}
+
+// recordImport records that the data of the language lang was just imported
+// from source. If arabic is true, the import of the arabic text, imported
+// along with the translation, is recorded too unless it already was.
+func recordImport(q querier, lang, source string, arabic bool) error {
+	if _, err := q.Exec(`
+		INSERT INTO Imports VALUES (?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(lang) DO UPDATE SET
+			source = excluded.source,
+			imported_at = excluded.imported_at`,
+		lang, source,
+	); err != nil {
+		return err
+	}
+
+	if arabic {
+		if _, err := q.Exec(`INSERT OR IGNORE INTO Imports VALUES (?, ?, CURRENT_TIMESTAMP)`, Arabic, source); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// sources returns the sources recorded for the languages.
+func sources(t *testing.T, c *Conn) map[string]string {
+	t.Helper()
+
+	infos, err := c.LanguageInfos()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sources := make(map[string]string)
+	for _, info := range infos {
+		sources[info.Lang] = info.Source
+	}
+
+	return sources
+}
+
+func TestRecordImportSource(t *testing.T) {
+	t.Parallel()
+
+	c := newDb(t)
+
+	translation := func(s, v int) string { return fmt.Sprintf("verse %d:%d", s, v) }
+
+	// the arabic text is imported along with the first translation.
+	if err := c.InitFromReader(strings.NewReader(quranJSON(t, translation)), "en", "https://example.com/quran_en.json", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var text strings.Builder
+	for s, count := range verseCounts {
+		for v := 1; v <= count; v++ {
+			fmt.Fprintf(&text, "%d|%d|vers %d:%d\n", s+1, v, s+1, v)
+		}
+	}
+
+	if err := c.ImportTanzilText(strings.NewReader(text.String()), "de", "/data/de.txt", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// the arabic text of a later translation is not recorded again.
+	if err := c.InitFromReader(strings.NewReader(quranJSON(t, translation)), "fr", "https://example.com/quran_fr.json", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		Arabic: "https://example.com/quran_en.json",
+		"en":   "https://example.com/quran_en.json",
+		"de":   "/data/de.txt",
+		"fr":   "https://example.com/quran_fr.json",
+	}
+
+	got := sources(t, c)
+
+	for lang, source := range want {
+		if got[lang] != source {
+			t.Errorf("source of %s = %q, want %q", lang, got[lang], source)
+		}
+	}
+}
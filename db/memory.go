@@ -0,0 +1,473 @@
+package db
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/vanillaiice/quran-cli/arabic"
+)
+
+// Memory is a store holding the data in memory, loaded from quran-json
+// data, for library users and tests that do not need a database.
+type Memory struct {
+	// surahs are the surahs in order, with their arabic text only.
+	surahs []*Surah
+	// surahTranslations are the translated names of the surahs by language.
+	surahTranslations map[string]map[int]string
+	// translations are the translations of the verses by language.
+	translations map[string]map[Position]string
+	// words are the words of the verses, in order.
+	words map[Position][]Word
+	// tafsirs are the tafsirs by name.
+	tafsirs map[string]*Tafsir
+	// commentaries are the commentaries of the tafsirs by
+	// tafsir name, ordered by their surah and first verse.
+	commentaries map[string][]*Commentary
+}
+
+// NewMemory returns an empty in-memory store.
+func NewMemory() *Memory {
+	return &Memory{
+		surahTranslations: make(map[string]map[int]string),
+		translations:      make(map[string]map[Position]string),
+		tafsirs:           make(map[string]*Tafsir),
+		commentaries:      make(map[string][]*Commentary),
+	}
+}
+
+// Close does nothing, the in-memory store having nothing to release.
+func (m *Memory) Close() error {
+	return nil
+}
+
+// InitFromReader loads the quran-json data read from r,
+// storing its translation under the language lang.
+// If progress is not nil, it is called after each loaded surah.
+func (m *Memory) InitFromReader(r io.Reader, lang string, progress Progress) error {
+	next, err := decodeSurahs(r)
+	if err != nil {
+		return err
+	}
+
+	var surahs []*Surah
+	surahTranslations := make(map[int]string)
+	translations := make(map[Position]string)
+
+	var verses int
+
+	for {
+		s, err := next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		if s.Translation != "" {
+			surahTranslations[s.Id] = s.Translation
+		}
+
+		for i, v := range s.Verses {
+			if v.Translation != "" {
+				translations[Position{s.Id, v.Id}] = v.Translation
+			}
+			s.Verses[i].Translation = ""
+		}
+
+		s.Translation = ""
+		surahs = append(surahs, s)
+
+		verses += len(s.Verses)
+
+		if progress != nil {
+			progress(min(verses, QuranVerses), QuranVerses)
+		}
+	}
+
+	if verses == 0 {
+		return errors.New("no verses to import")
+	}
+
+	sort.Slice(surahs, func(i, j int) bool {
+		return surahs[i].Id < surahs[j].Id
+	})
+
+	m.surahs = surahs
+
+	if lang != Arabic {
+		m.surahTranslations[lang] = surahTranslations
+		m.translations[lang] = translations
+	}
+
+	return nil
+}
+
+// InitFromFile loads the quran-json data of file,
+// storing its translation under the language lang.
+func (m *Memory) InitFromFile(file, lang string, progress Progress) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return m.InitFromReader(bufio.NewReader(f), lang, progress)
+}
+
+// HasLanguage returns true if the data of the language lang is in the store.
+func (m *Memory) HasLanguage(lang string) (bool, error) {
+	if lang == Arabic {
+		return len(m.surahs) > 0, nil
+	}
+	return len(m.translations[lang]) > 0, nil
+}
+
+// Languages returns the codes of the languages in the store.
+func (m *Memory) Languages() (langs []string, err error) {
+	if len(m.surahs) == 0 {
+		return
+	}
+
+	for lang := range m.translations {
+		langs = append(langs, lang)
+	}
+
+	sort.Strings(langs)
+
+	return append([]string{Arabic}, langs...), nil
+}
+
+// GetSurahById returns the surah with id id,
+// with its translation in the language lang.
+func (m *Memory) GetSurahById(id int, lang string) (*Surah, error) {
+	return m.GetVerses(id, 1, QuranVerses, lang)
+}
+
+// GetVerses returns the surah with id surahId, holding only its verses
+// from from to to, inclusive, with their translation in the language lang.
+func (m *Memory) GetVerses(surahId, from, to int, lang string) (*Surah, error) {
+	s := m.surah(surahId)
+	if s == nil {
+		return &Surah{}, nil
+	}
+
+	return m.translate(s, from, to, lang), nil
+}
+
+// GetSpan returns the surahs holding the verses of the span s, possibly
+// cut at its ends, with their translation in the language lang.
+func (m *Memory) GetSpan(s Span, lang string) (surahs []*Surah, err error) {
+	for _, surah := range m.surahs {
+		if surah.Id < s.From.Surah || surah.Id > s.To.Surah {
+			continue
+		}
+
+		from, to := 1, QuranVerses
+		if surah.Id == s.From.Surah {
+			from = s.From.Verse
+		}
+		if surah.Id == s.To.Surah {
+			to = s.To.Verse
+		}
+
+		if t := m.translate(surah, from, to, lang); len(t.Verses) > 0 {
+			surahs = append(surahs, t)
+		}
+	}
+
+	return
+}
+
+// GetSurahByName returns the surah with the transliterated name name,
+// with its translation in the language lang.
+func (m *Memory) GetSurahByName(name, lang string) (*Surah, error) {
+	for _, s := range m.surahs {
+		if s.Transliteration == name {
+			return m.GetSurahById(s.Id, lang)
+		}
+	}
+	return &Surah{}, nil
+}
+
+// ResolveSurah returns the id of the surah named name, as resolved by Conn.ResolveSurah.
+func (m *Memory) ResolveSurah(name, lang string) (int, error) {
+	surahs := make([]surahKeys, 0, len(m.surahs))
+
+	for _, s := range m.surahs {
+		surahs = append(surahs, newSurahKeys(s.Id, s.Transliteration, s.Name, m.surahTranslations[lang][s.Id], m.surahTranslations["en"][s.Id]))
+	}
+
+	return resolveSurah(name, surahs)
+}
+
+// GetTranslations returns the translations of a verse in the languages
+// langs, or in all the languages of the store if none is given.
+func (m *Memory) GetTranslations(surahId, verseId int, langs ...string) (map[string]string, error) {
+	if len(langs) == 0 {
+		for lang := range m.translations {
+			langs = append(langs, lang)
+		}
+	}
+
+	translations := make(map[string]string)
+
+	for _, lang := range langs {
+		if t, ok := m.translations[lang][Position{surahId, verseId}]; ok {
+			translations[lang] = t
+		}
+	}
+
+	return translations, nil
+}
+
+// Search returns the verses whose arabic text or translation in the
+// language lang contain all the words of the query, arabic matches first.
+// Unlike Conn.Search, the matches are not ranked by relevance.
+func (m *Memory) Search(query, lang string, limit int) (matches []*Match, err error) {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return nil, errors.New("empty search query")
+	}
+
+	normalized := searchTerms(arabic.Normalize(query))
+
+	for _, arabicText := range []bool{true, false} {
+		for _, s := range m.surahs {
+			for _, v := range s.Verses {
+				if limit >= 0 && len(matches) >= limit {
+					return
+				}
+
+				var snippet string
+				var ok bool
+
+				if arabicText {
+					snippet, ok = markTerms(arabic.Normalize(v.Text), normalized)
+				} else if t, found := m.translations[lang][Position{s.Id, v.Id}]; found {
+					snippet, ok = markTerms(t, terms)
+				}
+
+				if ok {
+					matches = append(matches, &Match{SurahId: s.Id, Transliteration: s.Transliteration, VerseId: v.Id, Snippet: snippet})
+				}
+			}
+		}
+	}
+
+	return
+}
+
+// ImportWords loads word-by-word data, replacing the words
+// already in the store, as imported by Conn.ImportWords.
+func (m *Memory) ImportWords(r io.Reader, progress Progress) error {
+	words := make(map[Position][]Word)
+
+	if err := decodeWords(r, progress, func(p Position, w Word) error {
+		words[p] = append(words[p], w)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for _, w := range words {
+		sort.Slice(w, func(i, j int) bool {
+			return w[i].Position < w[j].Position
+		})
+	}
+
+	m.words = words
+
+	return nil
+}
+
+// HasWords returns true if word-by-word data is in the store.
+func (m *Memory) HasWords() (bool, error) {
+	return len(m.words) > 0, nil
+}
+
+// GetWords returns the words of a verse, in order.
+func (m *Memory) GetWords(surahId, verseId int) ([]Word, error) {
+	return m.words[Position{surahId, verseId}], nil
+}
+
+// GetSpanWords returns the words of the verses of the span s,
+// in order, by verse. Verses without words are left out.
+func (m *Memory) GetSpanWords(s Span) (map[Position][]Word, error) {
+	words := make(map[Position][]Word)
+
+	for p, w := range m.words {
+		if !less(p, s.From) && !less(s.To, p) {
+			words[p] = w
+		}
+	}
+
+	return words, nil
+}
+
+// ImportTafsirJSON loads a tafsir in the JSON format,
+// as imported by Conn.ImportTafsirJSON.
+func (m *Memory) ImportTafsirJSON(r io.Reader, t Tafsir) error {
+	next, err := decodeTafsirJSON(r, &t)
+	if err != nil {
+		return err
+	}
+
+	return m.importTafsir(t, next)
+}
+
+// ImportTafsirCSV loads a tafsir in the CSV format,
+// as imported by Conn.ImportTafsirCSV.
+func (m *Memory) ImportTafsirCSV(r io.Reader, t Tafsir) error {
+	return m.importTafsir(t, decodeTafsirCSV(r))
+}
+
+// importTafsir loads the commentaries returned by next,
+// until io.EOF, as the tafsir t, replacing the existing one.
+func (m *Memory) importTafsir(t Tafsir, next func() (commentaryRecord, error)) error {
+	if err := checkTafsir(&t); err != nil {
+		return err
+	}
+
+	var commentaries []*Commentary
+
+	if err := readCommentaries(next, func(cm *Commentary) error {
+		commentaries = append(commentaries, cm)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	sort.Slice(commentaries, func(i, j int) bool {
+		a, b := commentaries[i], commentaries[j]
+		if a.SurahId != b.SurahId {
+			return a.SurahId < b.SurahId
+		}
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		return a.To < b.To
+	})
+
+	t.ImportedAt = time.Now().UTC().Truncate(time.Second)
+
+	m.tafsirs[t.Name] = &t
+	m.commentaries[t.Name] = commentaries
+
+	return nil
+}
+
+// Tafsirs returns the tafsirs in the store, sorted by name.
+func (m *Memory) Tafsirs() ([]*Tafsir, error) {
+	tafsirs := make([]*Tafsir, 0, len(m.tafsirs))
+
+	for _, t := range m.tafsirs {
+		tafsirs = append(tafsirs, t)
+	}
+
+	sort.Slice(tafsirs, func(i, j int) bool {
+		return tafsirs[i].Name < tafsirs[j].Name
+	})
+
+	return tafsirs, nil
+}
+
+// GetCommentaries returns the commentaries of the tafsir
+// name on a verse, ordered by their first verse.
+func (m *Memory) GetCommentaries(name string, surahId, verseId int) (commentaries []*Commentary, err error) {
+	for _, cm := range m.commentaries[name] {
+		if cm.SurahId == surahId && cm.From <= verseId && cm.To >= verseId {
+			commentaries = append(commentaries, cm)
+		}
+	}
+
+	return
+}
+
+// surah returns the surah with id id, or nil if it is not in the store.
+func (m *Memory) surah(id int) *Surah {
+	i := sort.Search(len(m.surahs), func(i int) bool {
+		return m.surahs[i].Id >= id
+	})
+
+	if i < len(m.surahs) && m.surahs[i].Id == id {
+		return m.surahs[i]
+	}
+
+	return nil
+}
+
+// translate returns a copy of the surah s, holding only its verses from
+// from to to, inclusive, with their translation in the language lang.
+func (m *Memory) translate(s *Surah, from, to int, lang string) *Surah {
+	t := *s
+	t.Translation = m.surahTranslations[lang][s.Id]
+	t.Verses = nil
+
+	for _, v := range s.Verses {
+		if v.Id < from || v.Id > to {
+			continue
+		}
+
+		v.Translation = m.translations[lang][Position{s.Id, v.Id}]
+		t.Verses = append(t.Verses, v)
+	}
+
+	return &t
+}
+
+// wordRune returns true if c is part of a word, as split
+// by the unicode61 tokenizer of the full-text indexes.
+func wordRune(c rune) bool {
+	return unicode.In(c, unicode.L, unicode.N, unicode.M, unicode.Co)
+}
+
+// searchTerms returns the lowercase words of the query,
+// split as by the unicode61 tokenizer, punctuation and
+// characters of the query syntax being left out.
+func searchTerms(query string) []string {
+	return strings.FieldsFunc(strings.ToLower(query), func(c rune) bool { return !wordRune(c) })
+}
+
+// markTerms surrounds the words of text matching the lowercase terms
+// with the snippet markers, returning false if some terms do not match.
+func markTerms(text string, terms []string) (string, bool) {
+	found := make(map[string]bool, len(terms))
+	wanted := make(map[string]bool, len(terms))
+	for _, t := range terms {
+		wanted[t] = true
+	}
+
+	var b strings.Builder
+
+	for len(text) > 0 {
+		i := strings.IndexFunc(text, func(c rune) bool { return !wordRune(c) })
+		if i == -1 {
+			i = len(text)
+		}
+
+		if w := text[:i]; wanted[strings.ToLower(w)] {
+			found[strings.ToLower(w)] = true
+			b.WriteString(SnippetStart + w + SnippetEnd)
+		} else {
+			b.WriteString(w)
+		}
+
+		text = text[i:]
+
+		j := strings.IndexFunc(text, wordRune)
+		if j == -1 {
+			j = len(text)
+		}
+
+		b.WriteString(text[:j])
+		text = text[j:]
+	}
+
+	return b.String(), len(found) == len(wanted)
+}
@@ -0,0 +1,176 @@
+package db
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// importer imports word-by-word data and tafsirs.
+type importer interface {
+	ImportWords(r io.Reader, progress Progress) error
+	ImportTafsirCSV(r io.Reader, t Tafsir) error
+}
+
+func TestMemoryMatchesConn(t *testing.T) {
+	t.Parallel()
+
+	c, m := newDb(t), NewMemory()
+
+	ar := quranJSON(t, nil)
+	en := quranJSON(t, func(s, v int) string {
+		if v == 0 {
+			return fmt.Sprintf("Surah %d", s)
+		}
+		return fmt.Sprintf("verse %d:%d", s, v)
+	})
+
+	words := "1:1:2\tٱللَّهِ\tl-lahi\t(of) Allah\n1:1:1\tبِسْمِ\tbis'mi\tIn (the) name\n" +
+		"1:7:1\tصِرَٰطَ\tsirata\t(The) path\n2:1:1\tالٓمٓ\talif-lam-meem\tAlif Laam Meem\n"
+	tafsir := "verses,text\n1:2,on the second verse\n1:1-7,on the opening\n"
+
+	for lang, data := range map[string]string{Arabic: ar, "en": en} {
+		if err := c.InitFromReader(strings.NewReader(data), lang, "quran.json", nil); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := m.InitFromReader(strings.NewReader(data), lang, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, s := range []importer{c, m} {
+		if err := s.ImportWords(strings.NewReader(words), nil); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := s.ImportTafsirCSV(strings.NewReader(tafsir), Tafsir{Name: "test", Lang: "en"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		get  func(s Store) (any, error)
+	}{
+		{"GetSurahById", func(s Store) (any, error) { return s.GetSurahById(112, "en") }},
+		{"GetVerses", func(s Store) (any, error) { return s.GetVerses(2, 255, 257, "en") }},
+		{"GetVerses arabic", func(s Store) (any, error) { return s.GetVerses(2, 255, 257, Arabic) }},
+		{"GetSpan", func(s Store) (any, error) {
+			return s.GetSpan(Span{Position{2, 286}, Position{3, 2}}, "en")
+		}},
+		{"GetSurahByName", func(s Store) (any, error) { return s.GetSurahByName("Surah-36", "en") }},
+		{"ResolveSurah", func(s Store) (any, error) { return s.ResolveSurah("surah 36", "en") }},
+		{"GetTranslations", func(s Store) (any, error) { return s.GetTranslations(1, 1) }},
+		{"Languages", func(s Store) (any, error) { return s.Languages() }},
+		{"HasWords", func(s Store) (any, error) { return s.HasWords() }},
+		{"GetWords", func(s Store) (any, error) { return s.GetWords(1, 1) }},
+		{"GetSpanWords", func(s Store) (any, error) {
+			return s.GetSpanWords(Span{Position{1, 1}, Position{1, 7}})
+		}},
+		{"GetSpanWords across surahs", func(s Store) (any, error) {
+			return s.GetSpanWords(Span{Position{1, 2}, Position{2, 1}})
+		}},
+		{"GetCommentaries", func(s Store) (any, error) { return s.GetCommentaries("test", 1, 2) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := tt.get(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := tt.get(m)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Memory.%s() = %+v, want %+v as Conn", tt.name, got, want)
+			}
+		})
+	}
+
+	spanWords, err := c.GetSpanWords(Span{Position{1, 2}, Position{2, 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(spanWords) != 2 || len(spanWords[Position{1, 7}]) != 1 || len(spanWords[Position{2, 1}]) != 1 {
+		t.Errorf("GetSpanWords() = %v, want the words of 1:7 and 2:1", spanWords)
+	}
+
+	tafsirs, err := m.Tafsirs()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tafsirs) != 1 || tafsirs[0].Name != "test" || tafsirs[0].Title != "test" || tafsirs[0].ImportedAt.IsZero() {
+		t.Errorf("Memory.Tafsirs() = %+v, want the imported tafsir", tafsirs)
+	}
+}
+
+func TestSearchMatchesConn(t *testing.T) {
+	t.Parallel()
+
+	c, m := newDb(t), NewMemory()
+
+	translations := map[Position]string{
+		{1, 3}:   "The Most Gracious, the Most Merciful.",
+		{2, 218}: "those hope for the mercy of Allah.",
+		{7, 156}: `"My mercy encompasses all things."`,
+	}
+
+	ar := quranJSON(t, nil)
+	en := quranJSON(t, func(s, v int) string {
+		if t, ok := translations[Position{s, v}]; ok {
+			return t
+		}
+		return fmt.Sprintf("verse %d:%d", s, v)
+	})
+
+	for lang, data := range map[string]string{Arabic: ar, "en": en} {
+		if err := c.InitFromReader(strings.NewReader(data), lang, "quran.json", nil); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := m.InitFromReader(strings.NewReader(data), lang, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	positions := func(matches []*Match) (p []Position) {
+		for _, m := range matches {
+			p = append(p, Position{m.SurahId, m.VerseId})
+		}
+		// the verses are ranked by Conn only.
+		sort.Slice(p, func(i, j int) bool { return less(p[i], p[j]) })
+		return
+	}
+
+	for _, query := range []string{"mercy", "mercy,", `"mercy"`, "MERCY!", "mercy of", "(most) merciful"} {
+		t.Run(query, func(t *testing.T) {
+			want, err := c.Search(query, "en", 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := m.Search(query, "en", 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(want) == 0 {
+				t.Fatalf("Conn.Search(%q) matched nothing", query)
+			}
+
+			if !reflect.DeepEqual(positions(got), positions(want)) {
+				t.Errorf("Memory.Search(%q) = %v, want %v as Conn", query, positions(got), positions(want))
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/vanillaiice/quran-cli/migrate"
+)
+
+// ErrSchemaTooNew is returned when opening a database created
+// by a newer version, whose schema is not understood.
+var ErrSchemaTooNew = migrate.ErrTooNew
+
+// ErrLegacySchema is returned when opening a database in the layout of
+// former versions, holding a single translation in the Quran and Verses
+// tables. Such databases are imported with Conn.ImportLegacy instead.
+var ErrLegacySchema = errors.New("database has the layout of a former version, import it instead of opening it")
+
+// migrations are the migrations of the database schema, in order.
+//
+// Migrations must never be changed once released,
+// schema changes are made by appending new ones.
+var migrations = []migrate.Migration{
+	// 1: arabic text, translations and full-text search indexes.
+	func(tx *sql.Tx) error {
+		// the tables of former versions have the same names,
+		// and would be left unchanged by the statements below.
+		var legacy bool
+		if err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM pragma_table_info('Quran') WHERE name = 'translation')`).Scan(&legacy); err != nil {
+			return err
+		}
+
+		if legacy {
+			return ErrLegacySchema
+		}
+
+		return migrate.Exec(`
+		CREATE TABLE IF NOT EXISTS Quran(
+			surah_id INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			transliteration TEXT NOT NULL,
+			type TEXT NOT NULL,
+			total_verses INTEGER NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS Verses(
+			id INTEGER PRIMARY KEY,
+			surah_id INTEGER NOT NULL,
+			verse_id INTEGER NOT NULL,
+			text TEXT NOT NULL,
+			UNIQUE (surah_id, verse_id),
+			FOREIGN KEY (surah_id) REFERENCES Quran(surah_id)
+		);
+
+		CREATE TABLE IF NOT EXISTS SurahTranslations(
+			lang TEXT NOT NULL,
+			surah_id INTEGER NOT NULL,
+			translation TEXT NOT NULL,
+			PRIMARY KEY (lang, surah_id),
+			FOREIGN KEY (surah_id) REFERENCES Quran(surah_id)
+		);
+
+		CREATE TABLE IF NOT EXISTS Translations(
+			lang TEXT NOT NULL,
+			surah_id INTEGER NOT NULL,
+			verse_id INTEGER NOT NULL,
+			text TEXT NOT NULL,
+			PRIMARY KEY (lang, surah_id, verse_id),
+			FOREIGN KEY (surah_id, verse_id) REFERENCES Verses(surah_id, verse_id)
+		);
+
+		CREATE VIRTUAL TABLE IF NOT EXISTS VersesFts USING fts5(
+			text,
+			content='Verses',
+			content_rowid='id'
+		);
+
+		CREATE TRIGGER IF NOT EXISTS verses_ai AFTER INSERT ON Verses BEGIN
+			INSERT INTO VersesFts(rowid, text) VALUES (new.id, new.text);
+		END;
+
+		CREATE TRIGGER IF NOT EXISTS verses_ad AFTER DELETE ON Verses BEGIN
+			INSERT INTO VersesFts(VersesFts, rowid, text) VALUES ('delete', old.id, old.text);
+		END;
+
+		CREATE TRIGGER IF NOT EXISTS verses_au AFTER UPDATE ON Verses BEGIN
+			INSERT INTO VersesFts(VersesFts, rowid, text) VALUES ('delete', old.id, old.text);
+			INSERT INTO VersesFts(rowid, text) VALUES (new.id, new.text);
+		END;
+
+		CREATE VIRTUAL TABLE IF NOT EXISTS TranslationsFts USING fts5(
+			text,
+			content='Translations',
+			content_rowid='rowid'
+		);
+
+		CREATE TRIGGER IF NOT EXISTS translations_ai AFTER INSERT ON Translations BEGIN
+			INSERT INTO TranslationsFts(rowid, text) VALUES (new.rowid, new.text);
+		END;
+
+		CREATE TRIGGER IF NOT EXISTS translations_ad AFTER DELETE ON Translations BEGIN
+			INSERT INTO TranslationsFts(TranslationsFts, rowid, text) VALUES ('delete', old.rowid, old.text);
+		END;
+
+		CREATE TRIGGER IF NOT EXISTS translations_au AFTER UPDATE ON Translations BEGIN
+			INSERT INTO TranslationsFts(TranslationsFts, rowid, text) VALUES ('delete', old.rowid, old.text);
+			INSERT INTO TranslationsFts(rowid, text) VALUES (new.rowid, new.text);
+		END;
+		`)(tx)
+	},
+	// 2: checksums of the imported texts, recorded for the data imported before.
+	func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS Checksums(
+			lang TEXT PRIMARY KEY,
+			sha256 TEXT NOT NULL
+		)`); err != nil {
+			return err
+		}
+
+		langs, err := languages(tx)
+		if err != nil {
+			return err
+		}
+
+		for _, lang := range langs {
+			if err = recordChecksum(tx, lang); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	},
+	// 3: word-by-word data.
+	migrate.Exec(`
+	CREATE TABLE IF NOT EXISTS Words(
+		surah_id INTEGER NOT NULL,
+		verse_id INTEGER NOT NULL,
+		position INTEGER NOT NULL,
+		text TEXT NOT NULL,
+		transliteration TEXT NOT NULL,
+		gloss TEXT NOT NULL,
+		PRIMARY KEY (surah_id, verse_id, position)
+	);
+	`),
+	// 4: morphology of the words.
+	migrate.Exec(`
+	CREATE TABLE IF NOT EXISTS Morphology(
+		surah_id INTEGER NOT NULL,
+		verse_id INTEGER NOT NULL,
+		position INTEGER NOT NULL,
+		root TEXT NOT NULL,
+		lemma TEXT NOT NULL,
+		pos TEXT NOT NULL,
+		PRIMARY KEY (surah_id, verse_id, position)
+	);
+
+	CREATE INDEX IF NOT EXISTS morphology_root ON Morphology(root);
+	`),
+	// 5: normalized arabic text, indexed for searching instead of the text.
+	func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`
+		DROP TRIGGER IF EXISTS verses_ai;
+		DROP TRIGGER IF EXISTS verses_ad;
+		DROP TRIGGER IF EXISTS verses_au;
+		DROP TABLE IF EXISTS VersesFts;
+
+		ALTER TABLE Verses ADD COLUMN normalized TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return err
+		}
+
+		if err := normalizeVerses(tx); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(`
+		CREATE VIRTUAL TABLE IF NOT EXISTS VersesFts USING fts5(
+			text UNINDEXED,
+			normalized,
+			content='Verses',
+			content_rowid='id'
+		);
+
+		CREATE TRIGGER IF NOT EXISTS verses_ai AFTER INSERT ON Verses BEGIN
+			INSERT INTO VersesFts(rowid, text, normalized) VALUES (new.id, new.text, new.normalized);
+		END;
+
+		CREATE TRIGGER IF NOT EXISTS verses_ad AFTER DELETE ON Verses BEGIN
+			INSERT INTO VersesFts(VersesFts, rowid, text, normalized) VALUES ('delete', old.id, old.text, old.normalized);
+		END;
+
+		CREATE TRIGGER IF NOT EXISTS verses_au AFTER UPDATE ON Verses BEGIN
+			INSERT INTO VersesFts(VersesFts, rowid, text, normalized) VALUES ('delete', old.id, old.text, old.normalized);
+			INSERT INTO VersesFts(rowid, text, normalized) VALUES (new.id, new.text, new.normalized);
+		END;
+
+		INSERT INTO VersesFts(VersesFts) VALUES ('rebuild');
+		`)
+
+		return err
+	},
+	// 6: source and date of the imports, unknown for the data imported before.
+	migrate.Exec(`
+	CREATE TABLE IF NOT EXISTS Imports(
+		lang TEXT PRIMARY KEY,
+		source TEXT NOT NULL,
+		imported_at TEXT
+	);
+
+	INSERT OR IGNORE INTO Imports(lang, source) SELECT lang, '' FROM Checksums;
+	`),
+	// 7: tafsirs, commentaries of ranges of verses.
+	migrate.Exec(`
+	CREATE TABLE IF NOT EXISTS Tafsirs(
+		name TEXT PRIMARY KEY,
+		title TEXT NOT NULL,
+		author TEXT NOT NULL,
+		lang TEXT NOT NULL,
+		source TEXT NOT NULL,
+		imported_at TEXT
+	);
+
+	CREATE TABLE IF NOT EXISTS Commentaries(
+		tafsir TEXT NOT NULL,
+		surah_id INTEGER NOT NULL,
+		from_verse INTEGER NOT NULL,
+		to_verse INTEGER NOT NULL,
+		text TEXT NOT NULL,
+		PRIMARY KEY (tafsir, surah_id, from_verse, to_verse),
+		FOREIGN KEY (tafsir) REFERENCES Tafsirs(name)
+	);
+	`),
+}
+
+// SchemaVersion returns the schema version of the databases created by this package.
+func SchemaVersion() int {
+	return len(migrations)
+}
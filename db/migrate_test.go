@@ -0,0 +1,122 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// legacyDb creates a database in the layout of former versions, from testdata/legacy.sql.
+func legacyDb(t *testing.T) string {
+	t.Helper()
+
+	stmts, err := os.ReadFile(filepath.Join("testdata", "legacy.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := filepath.Join(t.TempDir(), "quran_en.db")
+
+	conn, err := sql.Open("sqlite", file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if _, err = conn.Exec(string(stmts)); err != nil {
+		t.Fatal(err)
+	}
+
+	return file
+}
+
+// userVersion returns the schema version of the database file.
+func userVersion(t *testing.T, file string) (version int) {
+	t.Helper()
+
+	conn, err := sql.Open("sqlite", file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if err = conn.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		t.Fatal(err)
+	}
+
+	return
+}
+
+func TestNewMigrates(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "quran.db")
+
+	for range 2 {
+		c, err := New(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.Close()
+
+		if v := userVersion(t, file); v != SchemaVersion() {
+			t.Fatalf("schema version = %d, want %d", v, SchemaVersion())
+		}
+	}
+}
+
+func TestNewLegacySchema(t *testing.T) {
+	file := legacyDb(t)
+
+	if _, err := New(file); !errors.Is(err, ErrLegacySchema) {
+		t.Fatalf("New() error = %v, want %v", err, ErrLegacySchema)
+	}
+
+	if v := userVersion(t, file); v != 0 {
+		t.Fatalf("schema version = %d, want the legacy database left unchanged", v)
+	}
+}
+
+func TestImportLegacy(t *testing.T) {
+	legacy := legacyDb(t)
+
+	c, err := New(filepath.Join(t.TempDir(), "quran.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if err = c.ImportLegacy(legacy, "en"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, lang := range []string{Arabic, "en"} {
+		if ok, err := c.HasLanguage(lang); err != nil || !ok {
+			t.Fatalf("HasLanguage(%q) = %v, %v, want true", lang, ok, err)
+		}
+	}
+
+	s, err := c.GetVerses(112, 1, 4, "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(s.Verses) != 4 || s.Translation != "Sincerity" {
+		t.Fatalf("GetVerses() = %d verses, surah translation %q", len(s.Verses), s.Translation)
+	}
+
+	if v := s.Verses[0]; v.Text != "قُلْ هُوَ ٱللَّهُ أَحَدٌ" || v.Translation != "Say, He is Allah, One" {
+		t.Fatalf("verse 112:1 = %q, %q", v.Text, v.Translation)
+	}
+
+	infos, err := c.LanguageInfos()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, info := range infos {
+		if info.Source != legacy {
+			t.Errorf("source of %s = %q, want %q", info.Lang, info.Source, legacy)
+		}
+	}
+}
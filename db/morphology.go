@@ -0,0 +1,178 @@
+package db
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/vanillaiice/quran-cli/arabic"
+)
+
+// RootMatch is a verse holding words derived from a root.
+type RootMatch struct {
+	SurahId         int
+	Transliteration string
+	VerseId         int
+	// Words is the number of words of the verse derived from the root.
+	Words int
+	// Text is the translation of the verse, or its arabic text.
+	Text string
+}
+
+// ImportMorphology imports morphological data in the format of the Quranic
+// Arabic Corpus, replacing the data already in the database. The data is
+// tab separated, one segment of word per line, with the location of the
+// segment ((surah:verse:position:segment)), its form, its part of speech tag
+// and its features, holding the root and lemma of the word in the extended
+// Buckwalter transliteration. Empty lines and lines starting with # are skipped.
+// If progress is not nil, it is called after the words of each verse.
+func (c *Conn) ImportMorphology(r io.Reader, progress Progress) (err error) {
+	tx, err := c.db.Begin()
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec(`DELETE FROM Morphology`); err != nil {
+		return
+	}
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO Morphology VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(surah_id, verse_id, position) DO UPDATE SET
+			root = COALESCE(NULLIF(excluded.root, ''), root),
+			lemma = COALESCE(NULLIF(excluded.lemma, ''), lemma),
+			pos = COALESCE(NULLIF(excluded.pos, ''), pos)`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	scanner := bufio.NewScanner(r)
+
+	var n, verses int
+	var last Position
+
+	for scanner.Scan() {
+		n++
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "LOCATION") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) != 4 {
+			return fmt.Errorf("line %d: expected 4 fields, got %d", n, len(fields))
+		}
+
+		location := strings.TrimSuffix(strings.TrimPrefix(fields[0], "("), ")")
+
+		// the segment is not needed, the words being imported whole.
+		i := strings.LastIndex(location, ":")
+		if i == -1 {
+			return fmt.Errorf("line %d: invalid location %q", n, fields[0])
+		}
+
+		surahId, verseId, position, err := parseLocation(location[:i])
+		if err != nil {
+			return fmt.Errorf("line %d: %w", n, err)
+		}
+
+		var root, lemma, pos string
+
+		features := strings.Split(fields[3], "|")
+
+		// the part of speech of a word is the one of its stem.
+		if features[0] == "STEM" {
+			pos = fields[2]
+		}
+
+		for _, f := range features {
+			if v, ok := strings.CutPrefix(f, "ROOT:"); ok {
+				root = arabic.FromBuckwalter(v)
+			} else if v, ok := strings.CutPrefix(f, "LEM:"); ok {
+				lemma = arabic.FromBuckwalter(v)
+			}
+		}
+
+		if p := (Position{surahId, verseId}); p != last {
+			if progress != nil && verses > 0 {
+				progress(min(verses, QuranVerses), QuranVerses)
+			}
+			verses++
+			last = p
+		}
+
+		if _, err = stmt.Exec(surahId, verseId, position, root, lemma, pos); err != nil {
+			return err
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return
+	}
+
+	if verses == 0 {
+		return fmt.Errorf("no words to import")
+	}
+
+	if progress != nil {
+		progress(min(verses, QuranVerses), QuranVerses)
+	}
+
+	return tx.Commit()
+}
+
+// HasMorphology returns true if morphological data is in the database.
+func (c *Conn) HasMorphology() (ok bool, err error) {
+	err = c.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM Morphology)`).Scan(&ok)
+	return
+}
+
+// SearchRoot returns the verses holding words derived from the root root,
+// in order, with their translation in the language lang, or their
+// arabic text if lang is arabic.
+func (c *Conn) SearchRoot(root arabic.Root, lang string) ([]*RootMatch, error) {
+	rows, err := c.db.Query(`
+		SELECT
+			Morphology.surah_id,
+			Quran.transliteration,
+			Morphology.verse_id,
+			COUNT(*),
+			CASE WHEN ?1 = ?2 THEN Verses.text ELSE COALESCE(Translations.text, '') END
+		FROM Morphology
+		JOIN Quran
+		ON Quran.surah_id = Morphology.surah_id
+		JOIN Verses
+		ON Verses.surah_id = Morphology.surah_id
+		AND Verses.verse_id = Morphology.verse_id
+		LEFT JOIN Translations
+		ON Translations.surah_id = Morphology.surah_id
+		AND Translations.verse_id = Morphology.verse_id
+		AND Translations.lang = ?1
+		WHERE Morphology.root = ?3
+		GROUP BY Morphology.surah_id, Morphology.verse_id
+		ORDER BY Morphology.surah_id, Morphology.verse_id`,
+		lang, Arabic, string(root),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var matches []*RootMatch
+
+	for rows.Next() {
+		var m RootMatch
+
+		if err = rows.Scan(&m.SurahId, &m.Transliteration, &m.VerseId, &m.Words, &m.Text); err != nil {
+			return nil, err
+		}
+
+		matches = append(matches, &m)
+	}
+
+	return matches, rows.Err()
+}
@@ -0,0 +1,294 @@
+package db
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/vanillaiice/quran-cli/arabic"
+)
+
+// SurahNotFoundError is returned when a surah name does not resolve
+// to a single surah, along with the closest surahs if any.
+type SurahNotFoundError struct {
+	Name        string
+	Ambiguous   bool
+	Suggestions []string
+}
+
+// Error returns the error message, suggesting the closest surahs.
+func (e *SurahNotFoundError) Error() string {
+	msg := fmt.Sprintf("surah %q not found", e.Name)
+	if e.Ambiguous {
+		msg = fmt.Sprintf("surah %q is ambiguous", e.Name)
+	}
+
+	if len(e.Suggestions) > 0 {
+		msg += ", did you mean " + strings.Join(e.Suggestions, ", ") + "?"
+	}
+
+	return msg
+}
+
+// surahAliases are common names of surahs that
+// differ from their transliterated names.
+var surahAliases = map[string]int{
+	"opening":      1,
+	"cow":          2,
+	"imran":        3,
+	"women":        4,
+	"table":        5,
+	"cattle":       6,
+	"heights":      7,
+	"spoils":       8,
+	"baraah":       9,
+	"repentance":   9,
+	"baniisrail":   17,
+	"nightjourney": 17,
+	"cave":         18,
+	"mary":         19,
+	"prophets":     21,
+	"pilgrimage":   22,
+	"believers":    23,
+	"light":        24,
+	"criterion":    25,
+	"poets":        26,
+	"ant":          27,
+	"spider":       29,
+	"romans":       30,
+	"prostration":  32,
+	"confederates": 33,
+	"yasin":        36,
+	"mumin":        40,
+	"forgiver":     40,
+	"hamimsajdah":  41,
+	"smoke":        44,
+	"victory":      48,
+	"mostmerciful": 55,
+	"beneficent":   55,
+	"event":        56,
+	"iron":         57,
+	"sovereignty":  67,
+	"tabarak":      67,
+	"nun":          68,
+	"pen":          68,
+	"dahr":         76,
+	"man":          76,
+	"alamnashrah":  94,
+	"inshirah":     94,
+	"fig":          95,
+	"clot":         96,
+	"power":        97,
+	"earthquake":   99,
+	"time":         103,
+	"elephant":     105,
+	"abundance":    108,
+	"disbelievers": 109,
+	"help":         110,
+	"lahab":        111,
+	"palmfiber":    111,
+	"tawhid":       112,
+	"sincerity":    112,
+	"daybreak":     113,
+	"mankind":      114,
+}
+
+// prefixes matches the words that names may start with and that are
+// not matched: "surah", the arabic definite article, transliterated
+// as in al-, an- or ash-, and the english one.
+var prefixes = regexp.MustCompile(`^((surah|sura|سوره)[- ]*)?(a(l|n|r|s|t|d|z|sh|th|dh)[- ]|the |ال)?`)
+
+// surahKeys are the normalized names a surah can be resolved by.
+type surahKeys struct {
+	id   int
+	name string
+	keys []string
+}
+
+// newSurahKeys returns the keys of the surah with id id, named by
+// its transliterated name and the other names names, and its aliases.
+func newSurahKeys(id int, transliteration string, names ...string) surahKeys {
+	s := surahKeys{id: id, name: fmt.Sprintf("%s (#%d)", transliteration, id)}
+
+	for _, n := range append([]string{transliteration}, names...) {
+		s.keys = append(s.keys, nameKeys(n)...)
+	}
+
+	for alias, aliasId := range surahAliases {
+		if aliasId == id {
+			s.keys = append(s.keys, alias)
+		}
+	}
+
+	return s
+}
+
+// ResolveSurah returns the id of the surah named name, matching the
+// transliterated, arabic and translated names of the surahs in the
+// language lang or in english, and their common aliases, regardless of
+// case, articles and punctuation. Close names are accepted if they are
+// not ambiguous, a *SurahNotFoundError being returned otherwise.
+func (c *Conn) ResolveSurah(name, lang string) (int, error) {
+	rows, err := c.db.Query(`
+		SELECT
+			Quran.surah_id,
+			Quran.name,
+			Quran.transliteration,
+			COALESCE(Translated.translation, ''),
+			COALESCE(English.translation, '')
+		FROM Quran
+		LEFT JOIN SurahTranslations AS Translated
+		ON Translated.surah_id = Quran.surah_id
+		AND Translated.lang = ?
+		LEFT JOIN SurahTranslations AS English
+		ON English.surah_id = Quran.surah_id
+		AND English.lang = 'en'
+		ORDER BY Quran.surah_id`,
+		lang,
+	)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var surahs []surahKeys
+
+	for rows.Next() {
+		var id int
+		var arabicName, transliteration, translation, english string
+
+		if err = rows.Scan(&id, &arabicName, &transliteration, &translation, &english); err != nil {
+			return 0, err
+		}
+
+		surahs = append(surahs, newSurahKeys(id, transliteration, arabicName, translation, english))
+	}
+
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+
+	return resolveSurah(name, surahs)
+}
+
+// resolveSurah returns the id of the surah named name, ranking the
+// surahs by the edit distance between name and their closest key.
+func resolveSurah(name string, surahs []surahKeys) (int, error) {
+	queries := nameKeys(name)
+	if len(queries) == 0 {
+		return 0, &SurahNotFoundError{Name: name}
+	}
+
+	type candidate struct {
+		surah    surahKeys
+		distance int
+	}
+
+	candidates := make([]candidate, 0, len(surahs))
+
+	for _, s := range surahs {
+		best := -1
+
+		for _, k := range s.keys {
+			for _, q := range queries {
+				d := editDistance(q, k)
+
+				// names containing the query, as in imran for aliimran, are close.
+				if len([]rune(q)) >= 3 && strings.Contains(k, q) && d > 1 {
+					d = 1
+				}
+
+				if best == -1 || d < best {
+					best = d
+				}
+			}
+		}
+
+		if best != -1 {
+			candidates = append(candidates, candidate{s, best})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+
+	// maxDistance is the number of typos tolerated in the query.
+	maxDistance := 1 + len([]rune(queries[len(queries)-1]))/5
+
+	if len(candidates) > 0 && candidates[0].distance <= maxDistance {
+		if len(candidates) == 1 || candidates[1].distance > candidates[0].distance {
+			return candidates[0].surah.id, nil
+		}
+	}
+
+	err := &SurahNotFoundError{Name: name}
+
+	for _, c := range candidates {
+		if c.distance > maxDistance+1 || len(err.Suggestions) == 3 {
+			break
+		}
+
+		if c.distance == candidates[0].distance && c.distance <= maxDistance {
+			err.Ambiguous = true
+		}
+
+		err.Suggestions = append(err.Suggestions, c.surah.name)
+	}
+
+	return 0, err
+}
+
+// nameKeys returns the keys a name is matched by: the name, then the name
+// without its prefixes, both normalized regardless of case, punctuation
+// and, for arabic names, diacritics and orthography.
+func nameKeys(name string) (keys []string) {
+	name = strings.ToLower(arabic.Normalize(strings.TrimSpace(name)))
+
+	for _, n := range []string{name, prefixes.ReplaceAllString(name, "")} {
+		var b strings.Builder
+
+		for _, c := range n {
+			if unicode.IsLetter(c) || unicode.IsDigit(c) {
+				b.WriteRune(c)
+			}
+		}
+
+		if k := b.String(); k != "" && (len(keys) == 0 || keys[0] != k) {
+			keys = append(keys, k)
+		}
+	}
+
+	return
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, cur = cur, prev
+	}
+
+	return prev[len(rb)]
+}
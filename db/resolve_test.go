@@ -0,0 +1,118 @@
+package db
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNameKeys(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"Al-Baqarah", []string{"albaqarah", "baqarah"}},
+		{"  The Cow ", []string{"thecow", "cow"}},
+		{"Surah Al-Kahf", []string{"surahalkahf", "kahf"}},
+		{"sura kahf", []string{"surakahf", "kahf"}},
+		{"Ash-Shams", []string{"ashshams", "shams"}},
+		{"An Nas", []string{"annas", "nas"}},
+		{"Ali 'Imran", []string{"aliimran"}},
+		{"Ya-Sin", []string{"yasin"}},
+		{"الفاتحة", []string{"الفاتحه", "فاتحه"}},
+		{"سُورَةُ ٱلْكَهْفِ", []string{"سورهالكهف", "كهف"}},
+		{"112", []string{"112"}},
+		{"", nil},
+		{" - ", nil},
+	}
+
+	for _, tt := range tests {
+		if got := nameKeys(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("nameKeys(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// testSurahs are the keys of a few surahs, by transliterated,
+// arabic and english name.
+var testSurahs = []surahKeys{
+	newSurahKeys(1, "Al-Fatihah", "الفاتحة", "The Opener"),
+	newSurahKeys(2, "Al-Baqarah", "البقرة", "The Cow"),
+	newSurahKeys(3, "Ali 'Imran", "آل عمران", "Family of Imran"),
+	newSurahKeys(18, "Al-Kahf", "الكهف", "The Cave"),
+	newSurahKeys(36, "Ya-Sin", "يس", "Ya Sin"),
+	newSurahKeys(96, "Al-'Alaq", "العلق", "The Clot"),
+	newSurahKeys(112, "Al-Ikhlas", "الإخلاص", "The Sincerity"),
+	newSurahKeys(113, "Al-Falaq", "الفلق", "The Daybreak"),
+	newSurahKeys(114, "An-Nas", "الناس", "Mankind"),
+}
+
+func TestResolveSurah(t *testing.T) {
+	tests := []struct {
+		name string
+		want int
+	}{
+		{"Al-Baqarah", 2},
+		{"al baqarah", 2},
+		{"baqarah", 2},
+		{"baqara", 2},
+		{"bakara", 2},
+		{"the cow", 2},
+		{"cow", 2},
+		{"البقرة", 2},
+		{"البقره", 2},
+		{"fatiha", 1},
+		{"opening", 1},
+		{"imran", 3},
+		{"al-imran", 3},
+		{"surah kahf", 18},
+		{"cave", 18},
+		{"الْكَهْف", 18},
+		{"yasin", 36},
+		{"ya sin", 36},
+		{"alaq", 96},
+		{"falaq", 113},
+		{"falak", 113},
+		{"ikhlas", 112},
+		{"sincerity", 112},
+		{"الإخلاص", 112},
+		{"an-nas", 114},
+		{"nas", 114},
+		{"mankind", 114},
+	}
+
+	for _, tt := range tests {
+		got, err := resolveSurah(tt.name, testSurahs)
+		if err != nil || got != tt.want {
+			t.Errorf("resolveSurah(%q) = %d, %v, want %d", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestResolveSurahNotFound(t *testing.T) {
+	tests := []struct {
+		name        string
+		ambiguous   bool
+		suggestions []string
+	}{
+		{"", false, nil},
+		{"zzzzzzzzzz", false, nil},
+		{"kaxy", false, []string{"Al-Kahf (#18)"}},
+		{"imranxyz", false, []string{"Ali 'Imran (#3)"}},
+		{"ala", true, []string{"Al-'Alaq (#96)", "Al-Falaq (#113)"}},
+	}
+
+	for _, tt := range tests {
+		id, err := resolveSurah(tt.name, testSurahs)
+
+		var nf *SurahNotFoundError
+		if !errors.As(err, &nf) {
+			t.Errorf("resolveSurah(%q) = %d, %v, want a *SurahNotFoundError", tt.name, id, err)
+			continue
+		}
+
+		if nf.Ambiguous != tt.ambiguous || !reflect.DeepEqual(nf.Suggestions, tt.suggestions) {
+			t.Errorf("resolveSurah(%q) error = %+v, want ambiguous %v and suggestions %q", tt.name, nf, tt.ambiguous, tt.suggestions)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package db
+
+// SajdahMarker marks the verses of prostration.
+const SajdahMarker = "۩"
+
+// sajdahs are the verses of prostration, as marked in the Madani mushaf.
+var sajdahs = [15]Position{
+	{7, 206}, {13, 15}, {16, 50}, {17, 109}, {19, 58},
+	{22, 18}, {22, 77}, {25, 60}, {27, 26}, {32, 15},
+	{38, 24}, {41, 38}, {53, 62}, {84, 21}, {96, 19},
+}
+
+// Sajdahs returns the verses of prostration, in order.
+func Sajdahs() []Position {
+	return sajdahs[:]
+}
+
+// IsSajdah returns true if a verse is a verse of prostration.
+func IsSajdah(surah, verse int) bool {
+	for _, p := range sajdahs {
+		if p.Surah == surah && p.Verse == verse {
+			return true
+		}
+	}
+	return false
+}
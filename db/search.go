@@ -0,0 +1,145 @@
+package db
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/vanillaiice/quran-cli/arabic"
+)
+
+// markers surrounding the matched terms in a snippet.
+const (
+	SnippetStart = "\x02"
+	SnippetEnd   = "\x03"
+)
+
+// Match is a verse matching a search query.
+type Match struct {
+	SurahId         int
+	Transliteration string
+	VerseId         int
+	Snippet         string
+}
+
+// Search returns the verses whose arabic text or translation in the
+// language lang contain all the words of the query, the matches of the
+// arabic text first, then those of the translation, each best first, their
+// ranks coming from different indexes and not being comparable.
+// The arabic text is searched regardless of diacritics and orthography,
+// its snippets being taken from the normalized text.
+func (c *Conn) Search(query, lang string, limit int) ([]*Match, error) {
+	q := ftsQuery(query)
+	if q == "" {
+		return nil, errors.New("empty search query")
+	}
+
+	normalized := ftsQuery(arabic.Normalize(query))
+	if normalized == "" {
+		normalized = q
+	}
+
+	stmt := `
+		SELECT
+			Matches.surah_id,
+			Quran.transliteration,
+			Matches.verse_id,
+			Matches.snippet
+		FROM (
+			SELECT
+				Verses.surah_id,
+				Verses.verse_id,
+				snippet(VersesFts, 1, ?1, ?2, '…', 16) AS snippet,
+				0 AS translation,
+				VersesFts.rank AS rank
+			FROM VersesFts
+			JOIN Verses
+			ON Verses.id = VersesFts.rowid
+			WHERE VersesFts MATCH ?6
+			UNION ALL
+			SELECT
+				Translations.surah_id,
+				Translations.verse_id,
+				snippet(TranslationsFts, 0, ?1, ?2, '…', 16),
+				1,
+				TranslationsFts.rank
+			FROM TranslationsFts
+			JOIN Translations
+			ON Translations.rowid = TranslationsFts.rowid
+			WHERE TranslationsFts MATCH ?3
+			AND Translations.lang = ?4
+		) AS Matches
+		JOIN Quran
+		ON Quran.surah_id = Matches.surah_id
+		ORDER BY Matches.translation, Matches.rank
+		LIMIT ?5`
+
+	rows, err := c.db.Query(stmt, SnippetStart, SnippetEnd, q, lang, limit, normalized)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var matches []*Match
+
+	for rows.Next() {
+		var m Match
+
+		if err = rows.Scan(&m.SurahId, &m.Transliteration, &m.VerseId, &m.Snippet); err != nil {
+			return nil, err
+		}
+
+		matches = append(matches, &m)
+	}
+
+	return matches, rows.Err()
+}
+
+// ftsQuery converts free text to a full-text query
+// matching all of its words, quoting them so that
+// characters of the query syntax are taken literally.
+func ftsQuery(s string) string {
+	words := strings.Fields(s)
+
+	for i, w := range words {
+		words[i] = `"` + strings.ReplaceAll(w, `"`, `""`) + `"`
+	}
+
+	return strings.Join(words, " ")
+}
+
+// normalizeVerses normalizes the arabic text of
+// the verses that were not normalized yet.
+func normalizeVerses(q querier) error {
+	rows, err := q.Query(`SELECT id, text FROM Verses WHERE normalized = '' AND text != ''`)
+	if err != nil {
+		return err
+	}
+
+	normalized := make(map[int]string)
+
+	for rows.Next() {
+		var id int
+		var text string
+
+		if err = rows.Scan(&id, &text); err != nil {
+			rows.Close()
+			return err
+		}
+
+		normalized[id] = arabic.Normalize(text)
+	}
+
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for id, text := range normalized {
+		if _, err = q.Exec(`UPDATE Verses SET normalized = ? WHERE id = ?`, text, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package db
+
+// Store is a store of the Quran, with its translations, words and
+// tafsirs, that surahs and verses can be read and searched from.
+type Store interface {
+	// GetSurahById returns the surah with id id,
+	// with its translation in the language lang.
+	GetSurahById(id int, lang string) (*Surah, error)
+	// GetVerses returns the surah with id surahId, holding only its verses
+	// from from to to, inclusive, with their translation in the language lang.
+	GetVerses(surahId, from, to int, lang string) (*Surah, error)
+	// GetSpan returns the surahs holding the verses of the span s, possibly
+	// cut at its ends, with their translation in the language lang.
+	GetSpan(s Span, lang string) ([]*Surah, error)
+	// GetSurahByName returns the surah with the transliterated name name,
+	// with its translation in the language lang.
+	GetSurahByName(name, lang string) (*Surah, error)
+	// ResolveSurah returns the id of the surah named name.
+	ResolveSurah(name, lang string) (int, error)
+	// GetTranslations returns the translations of a verse in the languages
+	// langs, or in all the languages of the store if none is given.
+	GetTranslations(surahId, verseId int, langs ...string) (map[string]string, error)
+	// Search returns the verses whose arabic text or translation in the
+	// language lang contain all the words of the query, best matches first.
+	Search(query, lang string, limit int) ([]*Match, error)
+	// HasWords returns true if word-by-word data is in the store.
+	HasWords() (bool, error)
+	// GetWords returns the words of a verse, in order.
+	GetWords(surahId, verseId int) ([]Word, error)
+	// GetSpanWords returns the words of the verses of the span s,
+	// in order, by verse. Verses without words are left out.
+	GetSpanWords(s Span) (map[Position][]Word, error)
+	// Tafsirs returns the tafsirs in the store, sorted by name.
+	Tafsirs() ([]*Tafsir, error)
+	// GetCommentaries returns the commentaries of the tafsir
+	// name on a verse, ordered by their first verse.
+	GetCommentaries(name string, surahId, verseId int) ([]*Commentary, error)
+	// HasLanguage returns true if the data of the language lang is in the store.
+	HasLanguage(lang string) (bool, error)
+	// Languages returns the codes of the languages in the store.
+	Languages() ([]string, error)
+	// Close closes the store.
+	Close() error
+}
+
+var (
+	_ Store = (*Conn)(nil)
+	_ Store = (*Memory)(nil)
+)
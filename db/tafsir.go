@@ -0,0 +1,361 @@
+package db
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/vanillaiice/quran-cli/ref"
+)
+
+// Tafsir is a commentary of the Quran.
+type Tafsir struct {
+	// Name is the short name identifying the tafsir.
+	Name   string `json:"name"`
+	Title  string `json:"title"`
+	Author string `json:"author"`
+	// Lang is the code of the language of the commentary.
+	Lang string `json:"language"`
+	// Source is the location the tafsir was imported from.
+	Source string `json:"source,omitempty"`
+	// ImportedAt is the date of the import, zero if unknown.
+	ImportedAt time.Time `json:"-"`
+}
+
+// Commentary is the commentary of a tafsir on a range of verses of a surah.
+type Commentary struct {
+	SurahId int
+	From    int
+	To      int
+	Text    string
+}
+
+// tafsirFile is a tafsir in the JSON format.
+type tafsirFile struct {
+	Tafsir
+	Commentaries []commentaryRecord `json:"commentaries"`
+}
+
+// commentaryRecord is a commentary in the JSON and CSV formats,
+// its verses being referenced as in surah:from-to.
+type commentaryRecord struct {
+	Verses string `json:"verses"`
+	Text   string `json:"text"`
+}
+
+// ImportTafsirJSON imports a tafsir in the JSON format, replacing the tafsir
+// of the same name. The data is either an object holding the name, title,
+// author and language of the tafsir and its commentaries, or an array of
+// commentaries. Each commentary holds the verses it comments, referenced
+// as in 2:255 or 2:255-257, and its text. The fields of t that are not
+// empty take precedence over the ones of the data.
+func (c *Conn) ImportTafsirJSON(r io.Reader, t Tafsir) error {
+	next, err := decodeTafsirJSON(r, &t)
+	if err != nil {
+		return err
+	}
+
+	return c.importTafsir(t, next)
+}
+
+// ImportTafsirCSV imports a tafsir in the CSV format, replacing the tafsir
+// of the same name. Each record holds the verses a commentary comments,
+// referenced as in 2:255 or 2:255-257, and its text. A header record
+// (verses,text) and lines starting with # are skipped.
+func (c *Conn) ImportTafsirCSV(r io.Reader, t Tafsir) error {
+	return c.importTafsir(t, decodeTafsirCSV(r))
+}
+
+// decodeTafsirJSON decodes a tafsir in the JSON format read from r, setting
+// the empty fields of t to the ones of the data, and returns a function
+// returning its commentaries until io.EOF.
+func decodeTafsirJSON(r io.Reader, t *Tafsir) (next func() (commentaryRecord, error), err error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return
+	}
+
+	var f tafsirFile
+
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		err = json.Unmarshal(data, &f.Commentaries)
+	} else {
+		err = json.Unmarshal(data, &f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid tafsir data: %w", err)
+	}
+
+	merge(t, f.Tafsir)
+
+	i := 0
+
+	return func() (commentaryRecord, error) {
+		if i == len(f.Commentaries) {
+			return commentaryRecord{}, io.EOF
+		}
+		i++
+		return f.Commentaries[i-1], nil
+	}, nil
+}
+
+// decodeTafsirCSV returns a function returning the commentaries
+// of the tafsir in the CSV format read from r, until io.EOF.
+func decodeTafsirCSV(r io.Reader) func() (commentaryRecord, error) {
+	cr := csv.NewReader(bufio.NewReader(r))
+	cr.Comment = '#'
+	cr.FieldsPerRecord = 2
+
+	return func() (commentaryRecord, error) {
+		for {
+			record, err := cr.Read()
+			if err != nil {
+				return commentaryRecord{}, err
+			}
+
+			if strings.EqualFold(strings.TrimSpace(strings.TrimPrefix(record[0], "\ufeff")), "verses") {
+				continue
+			}
+
+			return commentaryRecord{Verses: record[0], Text: record[1]}, nil
+		}
+	}
+}
+
+// merge sets the empty fields of t to the ones of u.
+func merge(t *Tafsir, u Tafsir) {
+	if t.Name == "" {
+		t.Name = u.Name
+	}
+	if t.Title == "" {
+		t.Title = u.Title
+	}
+	if t.Author == "" {
+		t.Author = u.Author
+	}
+	if t.Lang == "" {
+		t.Lang = u.Lang
+	}
+	if t.Source == "" {
+		t.Source = u.Source
+	}
+}
+
+// importTafsir imports the commentaries returned by next,
+// until io.EOF, as the tafsir t, replacing the existing one.
+func (c *Conn) importTafsir(t Tafsir, next func() (commentaryRecord, error)) (err error) {
+	if err = checkTafsir(&t); err != nil {
+		return
+	}
+
+	tx, err := c.db.Begin()
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec(`DELETE FROM Commentaries WHERE tafsir = ?`, t.Name); err != nil {
+		return
+	}
+
+	if _, err = tx.Exec(`
+		INSERT INTO Tafsirs VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(name) DO UPDATE SET
+			title = excluded.title,
+			author = excluded.author,
+			lang = excluded.lang,
+			source = excluded.source,
+			imported_at = excluded.imported_at`,
+		t.Name, t.Title, t.Author, t.Lang, t.Source,
+	); err != nil {
+		return
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO Commentaries VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	if err = readCommentaries(next, func(cm *Commentary) error {
+		_, err := stmt.Exec(t.Name, cm.SurahId, cm.From, cm.To, cm.Text)
+		return err
+	}); err != nil {
+		return
+	}
+
+	return tx.Commit()
+}
+
+// checkTafsir checks that the tafsir t is named, titling it with its name if untitled.
+func checkTafsir(t *Tafsir) error {
+	if t.Name == "" {
+		return errors.New("missing tafsir name")
+	}
+
+	if t.Title == "" {
+		t.Title = t.Name
+	}
+
+	return nil
+}
+
+// readCommentaries parses the commentaries returned by next, until
+// io.EOF, passing each to add. It fails if several commentaries
+// comment the same verses, or if there is none.
+func readCommentaries(next func() (commentaryRecord, error), add func(cm *Commentary) error) error {
+	// seen holds the ranges of verses already commented.
+	seen := make(map[[3]int]bool)
+
+	for {
+		record, err := next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		cm, err := parseCommentary(record)
+		if err != nil {
+			return err
+		}
+
+		key := [3]int{cm.SurahId, cm.From, cm.To}
+		if seen[key] {
+			return fmt.Errorf("commentary %q: duplicate verses", record.Verses)
+		}
+		seen[key] = true
+
+		if err = add(cm); err != nil {
+			return fmt.Errorf("commentary %q: %w", record.Verses, err)
+		}
+	}
+
+	if len(seen) == 0 {
+		return errors.New("no commentaries to import")
+	}
+
+	return nil
+}
+
+// parseCommentary parses a commentary record.
+func parseCommentary(record commentaryRecord) (*Commentary, error) {
+	refs, err := ref.Parse(record.Verses)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(refs) != 1 || refs[0].Surah == 0 {
+		return nil, fmt.Errorf("commentary %q: expected the verses of a surah, referenced by number", record.Verses)
+	}
+
+	r := refs[0]
+	cm := &Commentary{SurahId: r.Surah, From: r.From, To: r.To, Text: strings.TrimSpace(record.Text)}
+
+	if r.Whole() {
+		cm.From, cm.To = 1, TotalVerses(r.Surah)
+	}
+
+	if cm.To > TotalVerses(r.Surah) {
+		return nil, fmt.Errorf("commentary %q: verse %d:%d does not exist", record.Verses, r.Surah, cm.To)
+	}
+
+	if cm.Text == "" {
+		return nil, fmt.Errorf("commentary %q: missing text", record.Verses)
+	}
+
+	return cm, nil
+}
+
+// Tafsirs returns the tafsirs in the database, sorted by name.
+func (c *Conn) Tafsirs() ([]*Tafsir, error) {
+	rows, err := c.db.Query(`SELECT name, title, author, lang, source, imported_at FROM Tafsirs ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tafsirs []*Tafsir
+
+	for rows.Next() {
+		var t Tafsir
+		var importedAt sql.NullString
+
+		if err = rows.Scan(&t.Name, &t.Title, &t.Author, &t.Lang, &t.Source, &importedAt); err != nil {
+			return nil, err
+		}
+
+		if importedAt.Valid {
+			if t.ImportedAt, err = time.ParseInLocation(timeLayout, importedAt.String, time.UTC); err != nil {
+				return nil, err
+			}
+		}
+
+		tafsirs = append(tafsirs, &t)
+	}
+
+	return tafsirs, rows.Err()
+}
+
+// GetCommentaries returns the commentaries of the tafsir
+// name on a verse, ordered by their first verse.
+func (c *Conn) GetCommentaries(name string, surahId, verseId int) ([]*Commentary, error) {
+	rows, err := c.db.Query(`
+		SELECT surah_id, from_verse, to_verse, text
+		FROM Commentaries
+		WHERE tafsir = ? AND surah_id = ? AND from_verse <= ?3 AND to_verse >= ?3
+		ORDER BY from_verse, to_verse`,
+		name, surahId, verseId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var commentaries []*Commentary
+
+	for rows.Next() {
+		var cm Commentary
+
+		if err = rows.Scan(&cm.SurahId, &cm.From, &cm.To, &cm.Text); err != nil {
+			return nil, err
+		}
+
+		commentaries = append(commentaries, &cm)
+	}
+
+	return commentaries, rows.Err()
+}
+
+// RemoveTafsir deletes the tafsir name.
+func (c *Conn) RemoveTafsir(name string) (err error) {
+	tx, err := c.db.Begin()
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec(`DELETE FROM Commentaries WHERE tafsir = ?`, name); err != nil {
+		return
+	}
+
+	res, err := tx.Exec(`DELETE FROM Tafsirs WHERE name = ?`, name)
+	if err != nil {
+		return
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("tafsir %q not found", name)
+	}
+
+	return tx.Commit()
+}
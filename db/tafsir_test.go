@@ -0,0 +1,178 @@
+package db
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCommentary(t *testing.T) {
+	tests := []struct {
+		verses string
+		text   string
+		want   *Commentary
+		err    bool
+	}{
+		{"2:255", "On the throne.", &Commentary{2, 255, 255, "On the throne."}, false},
+		{"2:255-257", "  trimmed \n", &Commentary{2, 255, 257, "trimmed"}, false},
+		{"112", "On the surah.", &Commentary{112, 1, 4, "On the surah."}, false},
+		{" 1 : 7 ", "text", &Commentary{1, 7, 7, "text"}, false},
+		{"1:1-7", "text", &Commentary{1, 1, 7, "text"}, false},
+		{"1:8", "text", nil, true},
+		{"1:6-8", "text", nil, true},
+		{"115:1", "text", nil, true},
+		{"al-fatihah:1", "text", nil, true},
+		{"1:1,1:2", "text", nil, true},
+		{"1:2-1", "text", nil, true},
+		{"", "text", nil, true},
+		{"1:1", "", nil, true},
+		{"1:1", " \n\t", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseCommentary(commentaryRecord{Verses: tt.verses, Text: tt.text})
+
+		if tt.err {
+			if err == nil {
+				t.Errorf("parseCommentary(%q, %q) = %+v, want an error", tt.verses, tt.text, got)
+			}
+			continue
+		}
+
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseCommentary(%q, %q) = %+v, %v, want %+v", tt.verses, tt.text, got, err, tt.want)
+		}
+	}
+}
+
+func TestReadCommentaries(t *testing.T) {
+	tests := []struct {
+		name    string
+		records []commentaryRecord
+		want    int
+		err     bool
+	}{
+		{"commentaries", []commentaryRecord{{"1:1", "a"}, {"1:1-7", "b"}, {"1:1-2", "c"}}, 3, false},
+		{"duplicate", []commentaryRecord{{"1:1-2", "a"}, {"1:1-2", "b"}}, 0, true},
+		{"whole surah duplicated", []commentaryRecord{{"112", "a"}, {"112:1-4", "b"}}, 0, true},
+		{"invalid", []commentaryRecord{{"1:1", "a"}, {"1:9", "b"}}, 0, true},
+		{"none", nil, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := 0
+			next := func() (commentaryRecord, error) {
+				if i == len(tt.records) {
+					return commentaryRecord{}, io.EOF
+				}
+				i++
+				return tt.records[i-1], nil
+			}
+
+			var n int
+
+			err := readCommentaries(next, func(cm *Commentary) error {
+				n++
+				return nil
+			})
+
+			if tt.err {
+				if err == nil {
+					t.Fatal("readCommentaries() error = nil, want an error")
+				}
+				return
+			}
+
+			if err != nil || n != tt.want {
+				t.Errorf("readCommentaries() = %d commentaries, %v, want %d", n, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportTafsirFormats(t *testing.T) {
+	tests := []struct {
+		name string
+		load func(m *Memory) error
+		want Tafsir
+	}{
+		{"json object", func(m *Memory) error {
+			return m.ImportTafsirJSON(strings.NewReader(`{"name": "test", "title": "Test", "author": "Someone", "language": "en",
+				"commentaries": [{"verses": "1:1-7", "text": "on the opening"}, {"verses": "1:2", "text": "on the second verse"}]}`), Tafsir{})
+		}, Tafsir{Name: "test", Title: "Test", Author: "Someone", Lang: "en"}},
+		{"json array", func(m *Memory) error {
+			return m.ImportTafsirJSON(strings.NewReader(`[{"verses": "1:1-7", "text": "on the opening"}, {"verses": "1:2", "text": "on the second verse"}]`),
+				Tafsir{Name: "test", Lang: "en", Source: "test.json"})
+		}, Tafsir{Name: "test", Title: "test", Lang: "en", Source: "test.json"}},
+		{"json with overrides", func(m *Memory) error {
+			return m.ImportTafsirJSON(strings.NewReader(`{"name": "other", "title": "Other", "language": "ar",
+				"commentaries": [{"verses": "1:1-7", "text": "on the opening"}, {"verses": "1:2", "text": "on the second verse"}]}`), Tafsir{Name: "test", Lang: "en"})
+		}, Tafsir{Name: "test", Title: "Other", Lang: "en"}},
+		{"csv", func(m *Memory) error {
+			return m.ImportTafsirCSV(strings.NewReader("\ufeffverses,text\n# comment\n1:1-7,on the opening\n\"1:2\",\"on the second verse\"\n"), Tafsir{Name: "test", Lang: "en"})
+		}, Tafsir{Name: "test", Title: "test", Lang: "en"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMemory()
+
+			if err := tt.load(m); err != nil {
+				t.Fatal(err)
+			}
+
+			tafsirs, err := m.Tafsirs()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(tafsirs) != 1 {
+				t.Fatalf("Tafsirs() = %+v, want one tafsir", tafsirs)
+			}
+
+			got := *tafsirs[0]
+			got.ImportedAt = tt.want.ImportedAt
+
+			if got != tt.want {
+				t.Errorf("tafsir = %+v, want %+v", got, tt.want)
+			}
+
+			commentaries, err := m.GetCommentaries("test", 1, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := []*Commentary{{1, 1, 7, "on the opening"}, {1, 2, 2, "on the second verse"}}
+			if !reflect.DeepEqual(commentaries, want) {
+				t.Errorf("GetCommentaries() = %+v, want %+v", commentaries, want)
+			}
+		})
+	}
+}
+
+func TestImportTafsirErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		t    Tafsir
+	}{
+		{"missing name", `[{"verses": "1:1", "text": "a"}]`, Tafsir{}},
+		{"invalid json", `{"name": "test",`, Tafsir{}},
+		{"no commentaries", `{"name": "test", "commentaries": []}`, Tafsir{}},
+		{"invalid verses", `[{"verses": "1:8", "text": "a"}]`, Tafsir{Name: "test"}},
+	}
+
+	for _, tt := range tests {
+		m := NewMemory()
+
+		if err := m.ImportTafsirJSON(strings.NewReader(tt.data), tt.t); err == nil {
+			t.Errorf("%s: ImportTafsirJSON() error = nil, want an error", tt.name)
+		}
+
+		if tafsirs, _ := m.Tafsirs(); len(tafsirs) != 0 {
+			t.Errorf("%s: Tafsirs() = %+v, want none imported", tt.name, tafsirs)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package db
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// tanzilVerse is a translated verse in a Tanzil format.
+type tanzilVerse struct {
+	Position
+	Text string
+	// Line is the line of the verse in the data.
+	Line int
+}
+
+// ImportTanzilText imports a translation in the Tanzil text format, with one
+// verse per line (sura|aya|text), storing it under the language lang and
+// recording source as the location it was imported from. Empty lines and
+// lines starting with # are skipped. The arabic text must already be in the
+// database. If progress is not nil, it is called after each surah.
+func (c *Conn) ImportTanzilText(r io.Reader, lang, source string, progress Progress) error {
+	return c.importTranslation(decodeTanzilText(r), lang, source, progress)
+}
+
+// decodeTanzilText returns a function returning the verses
+// of the translation in the Tanzil text format read from r.
+func decodeTanzilText(r io.Reader) func() (tanzilVerse, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var line int
+
+	return func() (v tanzilVerse, err error) {
+		for sc.Scan() {
+			line++
+
+			l := strings.TrimSpace(strings.TrimPrefix(sc.Text(), "\ufeff"))
+			if l == "" || strings.HasPrefix(l, "#") {
+				continue
+			}
+
+			fields := strings.SplitN(l, "|", 3)
+			if len(fields) != 3 {
+				return v, fmt.Errorf("line %d: expected sura|aya|text", line)
+			}
+
+			if v.Surah, err = strconv.Atoi(fields[0]); err != nil {
+				return v, fmt.Errorf("line %d: invalid sura %q", line, fields[0])
+			}
+
+			if v.Verse, err = strconv.Atoi(fields[1]); err != nil {
+				return v, fmt.Errorf("line %d: invalid aya %q", line, fields[1])
+			}
+
+			v.Text = strings.TrimSpace(fields[2])
+			v.Line = line
+
+			return v, nil
+		}
+
+		if err = sc.Err(); err != nil {
+			return
+		}
+
+		return v, io.EOF
+	}
+}
+
+// ImportTanzilXML imports a translation in the Tanzil XML format, with aya
+// elements holding the text of the verses in sura elements, storing it under
+// the language lang and recording source as the location it was imported
+// from. The arabic text must already be in the database. If progress is not
+// nil, it is called after each surah.
+func (c *Conn) ImportTanzilXML(r io.Reader, lang, source string, progress Progress) error {
+	return c.importTranslation(decodeTanzilXML(r), lang, source, progress)
+}
+
+// decodeTanzilXML returns a function returning the verses
+// of the translation in the Tanzil XML format read from r.
+func decodeTanzilXML(r io.Reader) func() (tanzilVerse, error) {
+	dec := xml.NewDecoder(r)
+
+	// number of the current surah.
+	var surah int
+
+	return func() (v tanzilVerse, err error) {
+		for {
+			t, err := dec.Token()
+			if err != nil {
+				return v, err
+			}
+
+			start, ok := t.(xml.StartElement)
+			if !ok {
+				continue
+			}
+
+			line, _ := dec.InputPos()
+
+			switch start.Name.Local {
+			case "sura":
+				if surah, err = intAttr(start, "index"); err != nil {
+					return v, fmt.Errorf("line %d: %w", line, err)
+				}
+			case "aya":
+				if surah == 0 {
+					return v, fmt.Errorf("line %d: aya element outside of a sura element", line)
+				}
+
+				v.Surah, v.Line = surah, line
+
+				if v.Verse, err = intAttr(start, "index"); err != nil {
+					return v, fmt.Errorf("line %d: sura %d: %w", line, surah, err)
+				}
+
+				for _, a := range start.Attr {
+					if a.Name.Local == "text" {
+						v.Text = strings.TrimSpace(a.Value)
+					}
+				}
+
+				return v, nil
+			}
+		}
+	}
+}
+
+// intAttr returns the integer value of the attribute name of the element e.
+func intAttr(e xml.StartElement, name string) (int, error) {
+	for _, a := range e.Attr {
+		if a.Name.Local == name {
+			n, err := strconv.Atoi(a.Value)
+			if err != nil {
+				return 0, fmt.Errorf("%s element: invalid %s %q", e.Name.Local, name, a.Value)
+			}
+			return n, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%s element: missing %s attribute", e.Name.Local, name)
+}
+
+// importTranslation imports the verses returned by next, until io.EOF,
+// as the translation in the language lang, replacing the existing one.
+// The translation must hold every verse of the Quran once.
+func (c *Conn) importTranslation(next func() (tanzilVerse, error), lang, source string, progress Progress) (err error) {
+	if lang == Arabic {
+		return errors.New("the arabic text cannot be imported as a translation")
+	}
+
+	ok, err := c.HasLanguage(Arabic)
+	if err != nil {
+		return
+	}
+
+	if !ok {
+		return errors.New("arabic text not found, initialize it first")
+	}
+
+	tx, err := c.db.Begin()
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	// the tanzil formats hold no translation of the surah names,
+	// so those of a former import are deleted along with it.
+	if _, err = tx.Exec(`DELETE FROM SurahTranslations WHERE lang = ?`, lang); err != nil {
+		return
+	}
+
+	// the translation being complete, every verse of an
+	// existing one is updated and none is left behind.
+	stmt, err := tx.Prepare(`
+		INSERT INTO Translations VALUES (?, ?, ?, ?)
+		ON CONFLICT(lang, surah_id, verse_id) DO UPDATE SET text = excluded.text
+		WHERE text != excluded.text`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	seen := make(map[Position]bool, QuranVerses)
+
+	for {
+		v, err := next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		if err = checkTanzilVerse(v, seen); err != nil {
+			return fmt.Errorf("line %d: %w", v.Line, err)
+		}
+
+		seen[v.Position] = true
+
+		if _, err = stmt.Exec(lang, v.Surah, v.Verse, v.Text); err != nil {
+			return err
+		}
+
+		if progress != nil && v.Verse == TotalVerses(v.Surah) {
+			progress(len(seen), QuranVerses)
+		}
+	}
+
+	if len(seen) != QuranVerses {
+		return fmt.Errorf("expected %d verses, got %d", QuranVerses, len(seen))
+	}
+
+	if err = recordChecksum(tx, lang); err != nil {
+		return
+	}
+
+	if err = recordImport(tx, lang, source, false); err != nil {
+		return
+	}
+
+	return tx.Commit()
+}
+
+// checkTanzilVerse checks that the verse v exists, has
+// a text and is not one of the verses already seen.
+func checkTanzilVerse(v tanzilVerse, seen map[Position]bool) error {
+	if v.Verse < 1 || v.Verse > TotalVerses(v.Surah) {
+		return fmt.Errorf("verse %s does not exist", v.Position)
+	}
+
+	if seen[v.Position] {
+		return fmt.Errorf("verse %s is duplicated", v.Position)
+	}
+
+	if v.Text == "" {
+		return fmt.Errorf("verse %s: missing text", v.Position)
+	}
+
+	return nil
+}
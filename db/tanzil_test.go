@@ -0,0 +1,150 @@
+package db
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// decodeAll returns the verses returned by next until io.EOF.
+func decodeAll(next func() (tanzilVerse, error)) (verses []tanzilVerse, err error) {
+	for {
+		v, err := next()
+		if err == io.EOF {
+			return verses, nil
+		} else if err != nil {
+			return verses, err
+		}
+		verses = append(verses, v)
+	}
+}
+
+func TestDecodeTanzilText(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []tanzilVerse
+		err  string
+	}{
+		{
+			"verses",
+			"\ufeff# translation\n\n1|1|In the name\n 1|2| Praise | be \n",
+			[]tanzilVerse{{Position{1, 1}, "In the name", 3}, {Position{1, 2}, "Praise | be", 4}},
+			"",
+		},
+		{"empty", "\n# nothing\n", nil, ""},
+		{"missing field", "1|1|a\n1|2\n", nil, "line 2: expected sura|aya|text"},
+		{"invalid sura", "\n\nx|1|a\n", nil, `line 3: invalid sura "x"`},
+		{"invalid aya", "1|y|a\n", nil, `line 1: invalid aya "y"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeAll(decodeTanzilText(strings.NewReader(tt.data)))
+
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("decodeTanzilText() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeTanzilText() = %+v, %v, want %+v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeTanzilXML(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []tanzilVerse
+		err  string
+	}{
+		{
+			"verses",
+			`<?xml version="1.0" encoding="utf-8"?>
+<quran>
+	<sura index="1" name="x">
+		<aya index="1" text="In the name"/>
+		<aya index="2" text=" Praise "/>
+	</sura>
+	<sura index="2" name="y">
+		<aya index="1" text="Alif"></aya>
+	</sura>
+</quran>`,
+			[]tanzilVerse{{Position{1, 1}, "In the name", 4}, {Position{1, 2}, "Praise", 5}, {Position{2, 1}, "Alif", 8}},
+			"",
+		},
+		{"aya outside sura", "<quran>\n<aya index=\"1\" text=\"a\"/>\n</quran>", nil, "line 2: aya element outside of a sura element"},
+		{"invalid sura", "<quran>\n\n<sura index=\"x\">\n</sura></quran>", nil, `line 3: sura element: invalid index "x"`},
+		{"missing aya index", "<quran>\n<sura index=\"1\">\n<aya text=\"a\"/>\n</sura></quran>", nil, "line 3: sura 1: aya element: missing index attribute"},
+		{"syntax error", "<quran>\n<sura index=\"1\">\n<aya index=\"1\" text=\"a\">\n</sura></quran>", nil, "XML syntax error on line 4: element <aya> closed by </sura>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeAll(decodeTanzilXML(strings.NewReader(tt.data)))
+
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("decodeTanzilXML() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeTanzilXML() = %+v, %v, want %+v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportTanzilErrors(t *testing.T) {
+	t.Parallel()
+
+	c := newDb(t)
+	initLang(t, c, Arabic, nil)
+
+	// lines returns the lines of a complete translation in the text format.
+	lines := func() (lines []string) {
+		for s, count := range verseCounts {
+			for v := 1; v <= count; v++ {
+				lines = append(lines, fmt.Sprintf("%d|%d|verse %d:%d", s+1, v, s+1, v))
+			}
+		}
+		return
+	}
+
+	tests := []struct {
+		name   string
+		change func(lines []string) []string
+		err    string
+	}{
+		{"missing verse", func(l []string) []string { return l[1:] }, fmt.Sprintf("expected %d verses, got %d", QuranVerses, QuranVerses-1)},
+		{"unknown verse", func(l []string) []string { return append(l[:2], append([]string{"1|8|verse"}, l[2:]...)...) }, "line 3: verse 1:8 does not exist"},
+		{"duplicated verse", func(l []string) []string { l[4] = "1|1|again"; return l }, "line 5: verse 1:1 is duplicated"},
+		{"missing text", func(l []string) []string { l[6] = "1|7| "; return l }, "line 7: verse 1:7: missing text"},
+		{"parse error", func(l []string) []string { l[9] = "2|3"; return l }, "line 10: expected sura|aya|text"},
+	}
+
+	for _, tt := range tests {
+		data := strings.Join(tt.change(lines()), "\n")
+
+		if err := c.ImportTanzilText(strings.NewReader(data), "en", "en.txt", nil); err == nil || err.Error() != tt.err {
+			t.Errorf("%s: ImportTanzilText() error = %v, want %q", tt.name, err, tt.err)
+		}
+	}
+
+	if verses, _ := countTranslations(t, c, "en"); verses != 0 {
+		t.Errorf("%d translated verses, want none imported", verses)
+	}
+
+	if err := c.ImportTanzilText(strings.NewReader(strings.Join(lines(), "\n")), "en", "en.txt", nil); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,27 @@
+-- a database in the layout of former versions, named quran_<lang>.db,
+-- holding the arabic text along with a single translation.
+CREATE TABLE Quran(
+	surah_id INTEGER PRIMARY KEY,
+	name TEXT NOT NULL,
+	transliteration TEXT NOT NULL,
+	translation TEXT NOT NULL,
+	type TEXT NOT NULL,
+	total_verses INTEGER NOT NULL
+);
+
+CREATE TABLE Verses(
+	id INTEGER PRIMARY KEY,
+	surah_id INTEGER NOT NULL,
+	verse_id INTEGER NOT NULL,
+	text TEXT NOT NULL,
+	translation TEXT NOT NULL,
+	FOREIGN KEY (surah_id) REFERENCES Quran(surah_id)
+);
+
+INSERT INTO Quran VALUES (112, 'الإخلاص', 'Al-Ikhlas', 'Sincerity', 'meccan', 4);
+
+INSERT INTO Verses VALUES
+	(6222, 112, 1, 'قُلْ هُوَ ٱللَّهُ أَحَدٌ', 'Say, He is Allah, One'),
+	(6223, 112, 2, 'ٱللَّهُ ٱلصَّمَدُ', 'Allah, the Eternal Refuge'),
+	(6224, 112, 3, 'لَمْ يَلِدْ وَلَمْ يُولَدْ', 'He neither begets nor is born'),
+	(6225, 112, 4, 'وَلَمْ يَكُن لَّهُۥ كُفُوًا أَحَدٌۢ', 'Nor is there to Him any equivalent');
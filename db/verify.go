@@ -0,0 +1,263 @@
+package db
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+)
+
+// querier runs queries on a database or in a transaction.
+type querier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// Problem is an integrity problem of the data of a language.
+type Problem struct {
+	Lang        string
+	Description string
+}
+
+// String returns the description of the problem, prefixed with its language.
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s", p.Lang, p.Description)
+}
+
+// Verify checks the integrity of the arabic text and of the translations,
+// returning the problems found. The texts are checked against the
+// checksums recorded when importing them and, for the languages in
+// known, against the checksums of their upstream data.
+func (c *Conn) Verify(known map[string]string) (problems []Problem, err error) {
+	langs, err := languages(c.db)
+	if err != nil {
+		return
+	}
+
+	if len(langs) == 0 {
+		return []Problem{{Arabic, "no data"}}, nil
+	}
+
+	for _, lang := range langs {
+		var descriptions []string
+
+		if lang == Arabic {
+			descriptions, err = verifyArabic(c.db)
+		} else {
+			descriptions, err = verifyTranslation(c.db, lang)
+		}
+		if err != nil {
+			return
+		}
+
+		var sum, recorded string
+
+		if sum, err = checksum(c.db, lang); err != nil {
+			return
+		}
+
+		err = c.db.QueryRow(`SELECT sha256 FROM Checksums WHERE lang = ?`, lang).Scan(&recorded)
+		if err == sql.ErrNoRows {
+			descriptions = append(descriptions, "no checksum recorded")
+		} else if err != nil {
+			return
+		} else if sum != recorded {
+			descriptions = append(descriptions, fmt.Sprintf("checksum %s does not match the recorded checksum %s", sum, recorded))
+		}
+
+		if upstream, ok := known[lang]; ok && sum != upstream {
+			descriptions = append(descriptions, fmt.Sprintf("checksum %s does not match the checksum %s of the upstream data", sum, upstream))
+		}
+
+		for _, d := range descriptions {
+			problems = append(problems, Problem{lang, d})
+		}
+	}
+
+	return problems, nil
+}
+
+// verifyArabic checks the surahs and the arabic text of their verses.
+func verifyArabic(q querier) (descriptions []string, err error) {
+	var surahs, verses, minId, maxId int
+
+	if err = q.QueryRow(`SELECT COUNT(*) FROM Quran`).Scan(&surahs); err != nil {
+		return
+	}
+
+	if surahs != len(verseCounts) {
+		descriptions = append(descriptions, fmt.Sprintf("%d surahs, expected %d", surahs, len(verseCounts)))
+	}
+
+	if err = q.QueryRow(`SELECT COUNT(*), COALESCE(MIN(id), 0), COALESCE(MAX(id), 0) FROM Verses`).Scan(&verses, &minId, &maxId); err != nil {
+		return
+	}
+
+	if verses != QuranVerses {
+		descriptions = append(descriptions, fmt.Sprintf("%d verses, expected %d", verses, QuranVerses))
+	}
+
+	if minId != 1 || maxId != verses {
+		descriptions = append(descriptions, "verse ids are not contiguous")
+	}
+
+	rows, err := q.Query(`
+		SELECT
+			Quran.surah_id,
+			Quran.total_verses,
+			COUNT(Verses.id),
+			COALESCE(MIN(Verses.verse_id), 0),
+			COALESCE(MAX(Verses.verse_id), 0)
+		FROM Quran
+		LEFT JOIN Verses
+		ON Verses.surah_id = Quran.surah_id
+		GROUP BY Quran.surah_id
+		ORDER BY Quran.surah_id`)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, total, count, first, last int
+
+		if err = rows.Scan(&id, &total, &count, &first, &last); err != nil {
+			return
+		}
+
+		if expected := TotalVerses(id); total != expected {
+			descriptions = append(descriptions, fmt.Sprintf("surah %d has %d total verses, expected %d", id, total, expected))
+		}
+
+		if count != total {
+			descriptions = append(descriptions, fmt.Sprintf("surah %d has %d verses, expected %d", id, count, total))
+		}
+
+		if count > 0 && (first != 1 || last != count) {
+			descriptions = append(descriptions, fmt.Sprintf("verse ids of surah %d are not contiguous", id))
+		}
+	}
+
+	return descriptions, rows.Err()
+}
+
+// verifyTranslation checks the translation of the verses in the language lang.
+func verifyTranslation(q querier, lang string) (descriptions []string, err error) {
+	var verses, orphans int
+
+	if err = q.QueryRow(`SELECT COUNT(*) FROM Translations WHERE lang = ?`, lang).Scan(&verses); err != nil {
+		return
+	}
+
+	if verses != QuranVerses {
+		descriptions = append(descriptions, fmt.Sprintf("%d translated verses, expected %d", verses, QuranVerses))
+	}
+
+	if err = q.QueryRow(`
+		SELECT COUNT(*)
+		FROM Translations
+		LEFT JOIN Verses
+		ON Verses.surah_id = Translations.surah_id
+		AND Verses.verse_id = Translations.verse_id
+		WHERE Translations.lang = ?
+		AND Verses.id IS NULL`, lang).Scan(&orphans); err != nil {
+		return
+	}
+
+	if orphans > 0 {
+		descriptions = append(descriptions, fmt.Sprintf("%d translated verses do not exist", orphans))
+	}
+
+	return
+}
+
+// checksum returns the SHA-256 checksum of the
+// arabic text or of the translation in language lang.
+func checksum(q querier, lang string) (string, error) {
+	var rows *sql.Rows
+	var err error
+
+	if lang == Arabic {
+		rows, err = q.Query(`SELECT surah_id, verse_id, text FROM Verses ORDER BY surah_id, verse_id`)
+	} else {
+		rows, err = q.Query(`SELECT surah_id, verse_id, text FROM Translations WHERE lang = ? ORDER BY surah_id, verse_id`, lang)
+	}
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	h := sha256.New()
+
+	for rows.Next() {
+		var surahId, verseId int
+		var text string
+
+		if err = rows.Scan(&surahId, &verseId, &text); err != nil {
+			return "", err
+		}
+
+		writeVerse(h, surahId, verseId, text)
+	}
+
+	if err = rows.Err(); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Checksum returns the checksum the arabic text, or the translation in
+// language lang, of the quran-json data read from r has once imported.
+func Checksum(r io.Reader, lang string) (string, error) {
+	next, err := decodeSurahs(r)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+
+	for {
+		s, err := next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return "", err
+		}
+
+		for _, v := range s.Verses {
+			if lang == Arabic {
+				writeVerse(h, s.Id, v.Id, v.Text)
+			} else if v.Translation != "" {
+				writeVerse(h, s.Id, v.Id, v.Translation)
+			}
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// writeVerse writes the text of a verse to the hash h of a checksum.
+func writeVerse(h hash.Hash, surahId, verseId int, text string) {
+	fmt.Fprintf(h, "%d:%d\t%s\n", surahId, verseId, text)
+}
+
+// recordChecksum records the checksum of the arabic
+// text or of the translation in language lang.
+func recordChecksum(q querier, lang string) error {
+	sum, err := checksum(q, lang)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.Exec(`
+		INSERT INTO Checksums VALUES (?, ?)
+		ON CONFLICT(lang) DO UPDATE SET sha256 = excluded.sha256`,
+		lang, sum,
+	)
+
+	return err
+}
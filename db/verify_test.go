@@ -0,0 +1,67 @@
+package db
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVerifyUpstreamChecksum(t *testing.T) {
+	t.Parallel()
+
+	c := newDb(t)
+
+	// the data was already bad when imported, its recorded checksum matches it.
+	bad := strings.Replace(quranJSON(t, nil), "بِسْمِ ٱللَّهِ 2:255", "altered", 1)
+	if err := c.InitFromReader(strings.NewReader(bad), Arabic, "quran.json", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	upstream, err := Checksum(strings.NewReader(quranJSON(t, nil)), Arabic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	problems, err := c.Verify(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(problems) != 0 {
+		t.Fatalf("Verify(nil) = %v, want no problems", problems)
+	}
+
+	if problems, err = c.Verify(map[string]string{Arabic: upstream}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(problems) != 1 || !strings.Contains(problems[0].Description, "upstream") {
+		t.Errorf("Verify() = %v, want the upstream checksum mismatch reported", problems)
+	}
+}
+
+func TestChecksumMatchesImport(t *testing.T) {
+	t.Parallel()
+
+	c := newDb(t)
+
+	translation := func(s, v int) string { return "verse" }
+
+	initLang(t, c, Arabic, nil)
+	initLang(t, c, "en", translation)
+
+	known := make(map[string]string)
+
+	for lang, data := range map[string]string{Arabic: quranJSON(t, nil), "en": quranJSON(t, translation)} {
+		sum, err := Checksum(strings.NewReader(data), lang)
+		if err != nil {
+			t.Fatal(err)
+		}
+		known[lang] = sum
+	}
+
+	if problems, err := c.Verify(known); err != nil {
+		t.Fatal(err)
+	} else if len(problems) != 0 {
+		t.Errorf("Verify() = %v, want no problems", problems)
+	}
+}
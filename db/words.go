@@ -0,0 +1,198 @@
+package db
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/vanillaiice/quran-cli/arabic"
+)
+
+// Word is a word of a verse, with its transliteration and translation,
+// and its morphology if it was imported.
+type Word struct {
+	Position        int
+	Text            string
+	Transliteration string
+	Gloss           string
+	Root            arabic.Root
+	Lemma           arabic.Lemma
+	// POS is the part of speech tag of the word.
+	POS string
+}
+
+// ImportWords imports word-by-word data, replacing the words already in the
+// database. The data is tab separated, one word per line, with the location
+// of the word (surah:verse:position), its arabic text, its transliteration
+// and its gloss. Empty lines and lines starting with # are skipped.
+// If progress is not nil, it is called after the words of each verse.
+func (c *Conn) ImportWords(r io.Reader, progress Progress) (err error) {
+	tx, err := c.db.Begin()
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec(`DELETE FROM Words`); err != nil {
+		return
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO Words VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	if err = decodeWords(r, progress, func(p Position, w Word) error {
+		_, err := stmt.Exec(p.Surah, p.Verse, w.Position, w.Text, w.Transliteration, w.Gloss)
+		return err
+	}); err != nil {
+		return
+	}
+
+	return tx.Commit()
+}
+
+// decodeWords decodes the word-by-word data read from r, passing each word
+// and the position of its verse to add. If progress is not nil, it is
+// called after the words of each verse.
+func decodeWords(r io.Reader, progress Progress, add func(p Position, w Word) error) error {
+	cr := csv.NewReader(r)
+	cr.Comma = '\t'
+	cr.Comment = '#'
+	cr.LazyQuotes = true
+	cr.FieldsPerRecord = 4
+
+	var verses int
+	var last Position
+
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		surahId, verseId, position, err := parseLocation(record[0])
+		if err != nil {
+			return err
+		}
+
+		if p := (Position{surahId, verseId}); p != last {
+			if progress != nil && verses > 0 {
+				progress(min(verses, QuranVerses), QuranVerses)
+			}
+			verses++
+			last = p
+		}
+
+		if err = add(last, Word{Position: position, Text: record[1], Transliteration: record[2], Gloss: record[3]}); err != nil {
+			return err
+		}
+	}
+
+	if verses == 0 {
+		return fmt.Errorf("no words to import")
+	}
+
+	if progress != nil {
+		progress(min(verses, QuranVerses), QuranVerses)
+	}
+
+	return nil
+}
+
+// HasWords returns true if word-by-word data is in the database.
+func (c *Conn) HasWords() (ok bool, err error) {
+	err = c.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM Words)`).Scan(&ok)
+	return
+}
+
+// selectWords selects the words with their morphology and
+// the position of their verse, to complete with a condition.
+const selectWords = `
+	SELECT
+		Words.surah_id,
+		Words.verse_id,
+		Words.position,
+		Words.text,
+		Words.transliteration,
+		Words.gloss,
+		COALESCE(Morphology.root, ''),
+		COALESCE(Morphology.lemma, ''),
+		COALESCE(Morphology.pos, '')
+	FROM Words
+	LEFT JOIN Morphology
+	ON Morphology.surah_id = Words.surah_id
+	AND Morphology.verse_id = Words.verse_id
+	AND Morphology.position = Words.position`
+
+// GetWords returns the words of a verse, in order.
+func (c *Conn) GetWords(surahId, verseId int) ([]Word, error) {
+	words, err := c.queryWords(selectWords+`
+		WHERE Words.surah_id = ? AND Words.verse_id = ?
+		ORDER BY Words.position`,
+		surahId, verseId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return words[Position{surahId, verseId}], nil
+}
+
+// GetSpanWords returns the words of the verses of the span s,
+// in order, by verse. Verses without words are left out.
+func (c *Conn) GetSpanWords(s Span) (map[Position][]Word, error) {
+	return c.queryWords(selectWords+`
+		WHERE (Words.surah_id, Words.verse_id) >= (?, ?)
+		AND (Words.surah_id, Words.verse_id) <= (?, ?)
+		ORDER BY Words.surah_id, Words.verse_id, Words.position`,
+		s.From.Surah, s.From.Verse, s.To.Surah, s.To.Verse,
+	)
+}
+
+// queryWords returns the words selected by the statement stmt, by verse.
+func (c *Conn) queryWords(stmt string, args ...any) (map[Position][]Word, error) {
+	rows, err := c.db.Query(stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	words := make(map[Position][]Word)
+
+	for rows.Next() {
+		var p Position
+		var w Word
+
+		if err = rows.Scan(&p.Surah, &p.Verse, &w.Position, &w.Text, &w.Transliteration, &w.Gloss, &w.Root, &w.Lemma, &w.POS); err != nil {
+			return nil, err
+		}
+
+		words[p] = append(words[p], w)
+	}
+
+	return words, rows.Err()
+}
+
+// parseLocation parses the location of a word, in the surah:verse:position notation.
+func parseLocation(s string) (surahId, verseId, position int, err error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) != 3 {
+		return 0, 0, 0, fmt.Errorf("invalid word location %q", s)
+	}
+
+	n := make([]int, len(parts))
+
+	for i, p := range parts {
+		if n[i], err = strconv.Atoi(p); err != nil || n[i] < 1 {
+			return 0, 0, 0, fmt.Errorf("invalid word location %q", s)
+		}
+	}
+
+	return n[0], n[1], n[2], nil
+}
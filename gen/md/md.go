@@ -11,7 +11,7 @@ import (
 const tmpl = `# Surah {{ .Id }} {{ .Name }} - {{ .Transliteration }}
 ## {{ .Type }} - {{ .TotalVerses }} verses
 	{{ range .Verses }}
-		{{ .Id }}. {{ .Translation }}
+		{{ .Id }}.{{ if sajdah $.Id .Id }} {{ marker }}{{ end }} {{ .Translation }}
 		{{ .Text }}
 		{{ end }}
 	> Surah {{ .Id }}: {{ .Transliteration }}
@@ -19,7 +19,10 @@ const tmpl = `# Surah {{ .Id }} {{ .Name }} - {{ .Transliteration }}
 
 // MakeTmpl generates the markdown template for the given surah.
 func MakeTmpl(s *db.Surah) (t string, err error) {
-	tmpl, err := template.New("tmpl").Parse(tmpl)
+	tmpl, err := template.New("tmpl").Funcs(template.FuncMap{
+		"sajdah": db.IsSajdah,
+		"marker": func() string { return db.SajdahMarker },
+	}).Parse(tmpl)
 	if err != nil {
 		return
 	}
@@ -0,0 +1,61 @@
+package migrate
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrTooNew is returned when migrating a database created
+// by a newer version, whose schema is not understood.
+var ErrTooNew = errors.New("database schema is newer than supported, please upgrade quran-cli")
+
+// Migration upgrades a database schema by one version.
+type Migration func(tx *sql.Tx) error
+
+// Exec returns a migration executing the statements stmt.
+func Exec(stmt string) Migration {
+	return func(tx *sql.Tx) error {
+		_, err := tx.Exec(stmt)
+		return err
+	}
+}
+
+// Run upgrades the schema of a database by running, in a single
+// transaction, the migrations it has not run yet.
+//
+// The schema version, stored in the user_version pragma,
+// is the number of migrations that ran on the database.
+func Run(conn *sql.DB, migrations []Migration) (err error) {
+	var version int
+	if err = conn.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return
+	}
+
+	if version > len(migrations) {
+		return fmt.Errorf("%w (version %d, supported %d)", ErrTooNew, version, len(migrations))
+	}
+
+	if version == len(migrations) {
+		return
+	}
+
+	tx, err := conn.Begin()
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	for i := version; i < len(migrations); i++ {
+		if err = migrations[i](tx); err != nil {
+			return fmt.Errorf("migrating database to version %d: %w", i+1, err)
+		}
+	}
+
+	// pragmas do not accept parameters.
+	if _, err = tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, len(migrations))); err != nil {
+		return
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,139 @@
+package migrate
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+// openDb opens a new database in a temporary directory.
+func openDb(t *testing.T) *sql.DB {
+	t.Helper()
+
+	conn, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+// version returns the schema version of a database.
+func version(t *testing.T, conn *sql.DB) (v int) {
+	t.Helper()
+
+	if err := conn.QueryRow(`PRAGMA user_version`).Scan(&v); err != nil {
+		t.Fatal(err)
+	}
+
+	return
+}
+
+// tables returns the names of the tables of a database.
+func tables(t *testing.T, conn *sql.DB) (names []string) {
+	t.Helper()
+
+	rows, err := conn.Query(`SELECT name FROM sqlite_master WHERE type = 'table' ORDER BY name`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+
+	return
+}
+
+func TestRun(t *testing.T) {
+	migrations := []Migration{
+		Exec(`CREATE TABLE A(id INTEGER)`),
+		Exec(`CREATE TABLE B(id INTEGER)`),
+		Exec(`CREATE TABLE C(id INTEGER)`),
+	}
+
+	tests := []struct {
+		name    string
+		initial int // number of migrations run before
+		version int
+		tables  []string
+	}{
+		{"new", 0, 3, []string{"A", "B", "C"}},
+		{"partial", 2, 3, []string{"A", "B", "C"}},
+		{"current", 3, 3, []string{"A", "B", "C"}},
+		{"first only", 0, 1, []string{"A"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := openDb(t)
+
+			if err := Run(conn, migrations[:tt.initial]); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := Run(conn, migrations[:tt.version]); err != nil {
+				t.Fatal(err)
+			}
+
+			if v := version(t, conn); v != tt.version {
+				t.Errorf("version = %d, want %d", v, tt.version)
+			}
+
+			if got := tables(t, conn); !slices.Equal(got, tt.tables) {
+				t.Errorf("tables = %v, want %v", got, tt.tables)
+			}
+		})
+	}
+}
+
+func TestRunTooNew(t *testing.T) {
+	conn := openDb(t)
+
+	if _, err := conn.Exec(`PRAGMA user_version = 5`); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Run(conn, []Migration{Exec(`CREATE TABLE A(id INTEGER)`)}); !errors.Is(err, ErrTooNew) {
+		t.Fatalf("Run() error = %v, want %v", err, ErrTooNew)
+	}
+
+	if v := version(t, conn); v != 5 {
+		t.Errorf("version = %d, want 5", v)
+	}
+
+	if got := tables(t, conn); len(got) != 0 {
+		t.Errorf("tables = %v, want none", got)
+	}
+}
+
+func TestRunRollback(t *testing.T) {
+	conn := openDb(t)
+
+	migrations := []Migration{
+		Exec(`CREATE TABLE A(id INTEGER)`),
+		Exec(`CREATE TABLE B(id INTEGER`), // syntax error
+	}
+
+	if err := Run(conn, migrations); err == nil {
+		t.Fatal("Run() error = nil, want the error of the second migration")
+	}
+
+	if v := version(t, conn); v != 0 {
+		t.Errorf("version = %d, want 0", v)
+	}
+
+	if got := tables(t, conn); len(got) != 0 {
+		t.Errorf("tables = %v, want the first migration rolled back", got)
+	}
+}
@@ -0,0 +1,117 @@
+package ref
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MaxSurahId is the maximum surah id in the Quran.
+const MaxSurahId = 114
+
+// Ref is a reference to a surah, or to a range of its verses.
+//
+// A surah is referenced either by its number or by its name,
+// and From and To are zero when the whole surah is referenced.
+type Ref struct {
+	Surah int    // number of the surah, zero if referenced by name
+	Name  string // name of the surah, empty if referenced by number
+	From  int    // first verse of the range
+	To    int    // last verse of the range
+}
+
+// Whole returns true if the reference is to the whole surah.
+func (r Ref) Whole() bool {
+	return r.From == 0
+}
+
+// String returns the reference in the notation accepted by Parse.
+func (r Ref) String() string {
+	s := r.Name
+	if s == "" {
+		s = strconv.Itoa(r.Surah)
+	}
+
+	switch {
+	case r.Whole():
+		return s
+	case r.From == r.To:
+		return fmt.Sprintf("%s:%d", s, r.From)
+	default:
+		return fmt.Sprintf("%s:%d-%d", s, r.From, r.To)
+	}
+}
+
+// Parse parses a comma separated list of references.
+//
+// Each reference is a surah number or name, optionally followed
+// by a colon and a verse or a range of verses, for example
+// "18", "2:255", "2:255-257" or "al-baqarah:255".
+func Parse(s string) (refs []Ref, err error) {
+	for _, item := range strings.Split(s, ",") {
+		r, err := parseOne(strings.TrimSpace(item))
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, r)
+	}
+
+	return
+}
+
+// parseOne parses a single reference.
+func parseOne(s string) (r Ref, err error) {
+	if s == "" {
+		return r, fmt.Errorf("empty reference")
+	}
+
+	surah, verses, hasVerses := strings.Cut(s, ":")
+
+	surah = strings.TrimSpace(surah)
+	if surah == "" {
+		return r, fmt.Errorf("missing surah in reference %q", s)
+	}
+
+	if n, err := strconv.Atoi(surah); err == nil {
+		if n < 1 || n > MaxSurahId {
+			return r, fmt.Errorf("invalid surah number %d in reference %q", n, s)
+		}
+		r.Surah = n
+	} else {
+		r.Name = surah
+	}
+
+	if !hasVerses {
+		return
+	}
+
+	from, to, isRange := strings.Cut(verses, "-")
+
+	if r.From, err = parseVerse(from, s); err != nil {
+		return
+	}
+
+	if !isRange {
+		r.To = r.From
+		return
+	}
+
+	if r.To, err = parseVerse(to, s); err != nil {
+		return
+	}
+
+	if r.To < r.From {
+		return r, fmt.Errorf("invalid verse range in reference %q", s)
+	}
+
+	return
+}
+
+// parseVerse parses a verse number of the reference ref.
+func parseVerse(s, ref string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid verse %q in reference %q", s, ref)
+	}
+	return n, nil
+}
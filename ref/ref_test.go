@@ -0,0 +1,76 @@
+package ref
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []Ref
+		err  bool
+	}{
+		{"18", []Ref{{Surah: 18}}, false},
+		{"2:255", []Ref{{Surah: 2, From: 255, To: 255}}, false},
+		{"2:255-257", []Ref{{Surah: 2, From: 255, To: 257}}, false},
+		{"al-baqarah:255", []Ref{{Name: "al-baqarah", From: 255, To: 255}}, false},
+		{"al-kahf", []Ref{{Name: "al-kahf"}}, false},
+		{"yusuf : 3 - 5", []Ref{{Name: "yusuf", From: 3, To: 5}}, false},
+		{"1, 2:255 ,114", []Ref{{Surah: 1}, {Surah: 2, From: 255, To: 255}, {Surah: 114}}, false},
+		{"1:1-1", []Ref{{Surah: 1, From: 1, To: 1}}, false},
+		{"114", []Ref{{Surah: 114}}, false},
+		{"", nil, true},
+		{" ", nil, true},
+		{"1,", nil, true},
+		{"0", nil, true},
+		{"115", nil, true},
+		{"-1", nil, true},
+		{":5", nil, true},
+		{"2:", nil, true},
+		{"2:0", nil, true},
+		{"2:a", nil, true},
+		{"2:-5", nil, true},
+		{"2:5-", nil, true},
+		{"2:7-5", nil, true},
+		{"2:1-2-3", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+
+		if tt.err {
+			if err == nil {
+				t.Errorf("Parse(%q) = %v, want an error", tt.in, got)
+			}
+			continue
+		}
+
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%q) = %v, %v, want %v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestRefString(t *testing.T) {
+	tests := []struct {
+		ref  Ref
+		want string
+	}{
+		{Ref{Surah: 18}, "18"},
+		{Ref{Surah: 2, From: 255, To: 255}, "2:255"},
+		{Ref{Surah: 2, From: 255, To: 257}, "2:255-257"},
+		{Ref{Name: "al-baqarah", From: 1, To: 5}, "al-baqarah:1-5"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.ref.String(); got != tt.want {
+			t.Errorf("%#v.String() = %q, want %q", tt.ref, got, tt.want)
+		}
+
+		// the notation is parsed back to the reference.
+		if refs, err := Parse(tt.want); err != nil || len(refs) != 1 || refs[0] != tt.ref {
+			t.Errorf("Parse(%q) = %v, %v, want %v", tt.want, refs, err, tt.ref)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package source
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// default settings of the downloads.
+const (
+	DefaultTimeout = time.Minute
+	DefaultRetries = 3
+	DefaultBackoff = time.Second
+)
+
+// Downloader fetches the data of the sources, from their url or, if it
+// fails, from their mirrors, verifying it against their pinned hash.
+type Downloader struct {
+	// Client is the client of the downloads.
+	Client *http.Client
+	// Retries is the number of times a failed download of an url is retried.
+	Retries int
+	// Backoff is the delay before the first retry, doubled after each one.
+	Backoff time.Duration
+	// OnRetry, if not nil, is called with the error of a download before retrying it.
+	OnRetry func(url string, err error)
+	// OnProgress, if not nil, is called while downloading with the number of
+	// bytes downloaded and the total number of bytes, or -1 if unknown.
+	OnProgress func(url string, done, total int64)
+}
+
+// NewDownloader returns a downloader with the default retries, whose
+// downloads time out after timeout, or never if it is 0. The downloads go
+// through the proxy url proxy if not empty, else through the proxy set
+// by the HTTP_PROXY and HTTPS_PROXY environment variables, if any.
+func NewDownloader(timeout time.Duration, proxy string) (*Downloader, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if proxy != "" {
+		u, err := url.Parse(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(u)
+	}
+
+	return &Downloader{
+		Client:  &http.Client{Timeout: timeout, Transport: transport},
+		Retries: DefaultRetries,
+		Backoff: DefaultBackoff,
+	}, nil
+}
+
+// ErrChecksum is returned when the hash of the data of a source is not the pinned one.
+var ErrChecksum = errors.New("sha256 mismatch")
+
+// statusError is returned when a download fails with an unexpected status.
+type statusError struct {
+	code   int
+	status string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("unexpected status %s", e.status)
+}
+
+// Open returns a reader of the data of the source s, trying its url then
+// its mirrors in order. The data is read in full and verified against the
+// pinned hash of the source, if any, before being returned.
+func (d *Downloader) Open(s Source) (io.ReadCloser, error) {
+	var errs []error
+
+	for _, location := range append([]string{s.URL}, s.Mirrors...) {
+		data, err := d.fetch(location, s.SHA256)
+		if err == nil {
+			return io.NopCloser(bytes.NewReader(data)), nil
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", location, err))
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+// fetch returns the data at location, an url or a local path, checking that
+// its hash is sum if not empty. Failed downloads are retried with backoff,
+// unless the server reports that the data does not exist.
+func (d *Downloader) fetch(location, sum string) (data []byte, err error) {
+	if !remote(location) {
+		if data, err = os.ReadFile(strings.TrimPrefix(location, "file://")); err != nil {
+			return
+		}
+		return data, verify(data, sum)
+	}
+
+	backoff := d.Backoff
+
+	for attempt := 0; ; attempt++ {
+		if data, err = d.get(location); err == nil {
+			if err = verify(data, sum); err == nil {
+				return
+			}
+		}
+
+		if attempt >= d.Retries || !retryable(err) {
+			return nil, err
+		}
+
+		if d.OnRetry != nil {
+			d.OnRetry(location, err)
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// get downloads the data at the url u.
+func (d *Downloader) get(u string) ([]byte, error) {
+	client := d.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &statusError{code: resp.StatusCode, status: resp.Status}
+	}
+
+	var r io.Reader = resp.Body
+	if d.OnProgress != nil {
+		r = &progressReader{r: resp.Body, progress: func(done int64) {
+			d.OnProgress(u, done, resp.ContentLength)
+		}}
+	}
+
+	// a body shorter than its announced length fails with io.ErrUnexpectedEOF.
+	return io.ReadAll(r)
+}
+
+// progressReader reads from r, calling progress with the number of bytes read.
+type progressReader struct {
+	r        io.Reader
+	done     int64
+	progress func(done int64)
+}
+
+func (p *progressReader) Read(b []byte) (n int, err error) {
+	n, err = p.r.Read(b)
+	if n > 0 {
+		p.done += int64(n)
+		p.progress(p.done)
+	}
+	return
+}
+
+// retryable returns true if the download failing with err may succeed if retried.
+func retryable(err error) bool {
+	if errors.Is(err, ErrChecksum) {
+		return false
+	}
+
+	var s *statusError
+	if errors.As(err, &s) {
+		return s.code >= 500 || s.code == http.StatusRequestTimeout || s.code == http.StatusTooManyRequests
+	}
+	return true
+}
+
+// verify checks that the SHA-256 hash of data is sum, if not empty.
+func verify(data []byte, sum string) error {
+	if sum == "" {
+		return nil
+	}
+
+	h := sha256.Sum256(data)
+	if got := hex.EncodeToString(h[:]); got != sum {
+		return fmt.Errorf("%w: expected %s, got %s", ErrChecksum, sum, got)
+	}
+
+	return nil
+}
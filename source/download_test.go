@@ -0,0 +1,281 @@
+package source
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const data = "quran-json data"
+
+// sum is the SHA-256 hash of data.
+var sum = func() string {
+	h := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(h[:])
+}()
+
+// server returns a server answering each request with the
+// next of statuses, the last one being repeated, and the number
+// of requests it served. The successful answers hold body.
+func server(t *testing.T, body string, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var n atomic.Int32
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := int(n.Add(1)) - 1
+
+		status := statuses[min(i, len(statuses)-1)]
+		if status != http.StatusOK {
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(s.Close)
+
+	return s, &n
+}
+
+// downloader returns a downloader retrying quickly.
+func downloader() *Downloader {
+	return &Downloader{Retries: DefaultRetries, Backoff: time.Millisecond}
+}
+
+// read reads the data of the source s with the downloader d.
+func read(d *Downloader, s Source) (string, error) {
+	r, err := d.Open(s)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	b, err := io.ReadAll(r)
+	return string(b), err
+}
+
+func TestOpenStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		requests int32
+		status   int // status of the error, 0 if the download succeeds
+	}{
+		{"ok", []int{200}, 1, 0},
+		{"not found", []int{404}, 1, 404},
+		{"forbidden", []int{403}, 1, 403},
+		{"server error", []int{500}, DefaultRetries + 1, 500},
+		{"server error then ok", []int{500, 503, 200}, 3, 0},
+		{"too many requests then ok", []int{429, 200}, 2, 0},
+		{"timeout then ok", []int{408, 200}, 2, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, n := server(t, data, tt.statuses...)
+
+			var retries int32
+
+			d := downloader()
+			d.OnRetry = func(url string, err error) { retries++ }
+
+			got, err := read(d, Source{Code: "en", URL: s.URL})
+
+			if tt.status == 0 {
+				if err != nil || got != data {
+					t.Fatalf("Open() = %q, %v, want %q", got, err, data)
+				}
+			} else {
+				var se *statusError
+				if !errors.As(err, &se) || se.code != tt.status {
+					t.Fatalf("Open() error = %v, want status %d", err, tt.status)
+				}
+			}
+
+			if n.Load() != tt.requests {
+				t.Errorf("requests = %d, want %d", n.Load(), tt.requests)
+			}
+
+			if retries != tt.requests-1 {
+				t.Errorf("retries = %d, want %d", retries, tt.requests-1)
+			}
+		})
+	}
+}
+
+func TestOpenBackoff(t *testing.T) {
+	s, _ := server(t, data, 500)
+
+	d := downloader()
+	d.Backoff = 10 * time.Millisecond
+
+	start := time.Now()
+
+	if _, err := read(d, Source{Code: "en", URL: s.URL}); err == nil {
+		t.Fatal("Open() error = nil, want an error")
+	}
+
+	// the delays are doubled after each retry: 10, 20 and 40ms.
+	if elapsed := time.Since(start); elapsed < 70*time.Millisecond {
+		t.Errorf("retried after %v, want at least 70ms", elapsed)
+	}
+}
+
+func TestOpenChecksum(t *testing.T) {
+	tests := []struct {
+		name     string
+		sum      string
+		err      error
+		requests int32
+	}{
+		{"no pin", "", nil, 1},
+		{"pinned", sum, nil, 1},
+		{"mismatch", strings.Repeat("0", 64), ErrChecksum, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, n := server(t, data, 200)
+
+			_, err := read(downloader(), Source{Code: "en", URL: s.URL, SHA256: tt.sum})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Open() error = %v, want %v", err, tt.err)
+			}
+
+			if n.Load() != tt.requests {
+				t.Errorf("requests = %d, want %d", n.Load(), tt.requests)
+			}
+		})
+	}
+}
+
+func TestOpenMirrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		main   int
+		mirror int
+		body   string // body of the mirror
+		err    bool
+	}{
+		{"main", 200, 200, "mirror data", false},
+		{"mirror after not found", 404, 200, data, false},
+		{"mirror after server error", 500, 200, data, false},
+		{"mirror with bad checksum", 404, 200, "corrupted", true},
+		{"both failing", 404, 410, data, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			main, _ := server(t, data, tt.main)
+			mirror, n := server(t, tt.body, tt.mirror)
+
+			got, err := read(downloader(), Source{Code: "en", URL: main.URL, Mirrors: []string{mirror.URL}, SHA256: sum})
+
+			if tt.err {
+				if err == nil {
+					t.Fatalf("Open() = %q, want an error", got)
+				}
+
+				// the error reports the failure of every location.
+				for _, u := range []string{main.URL, mirror.URL} {
+					if !strings.Contains(err.Error(), u) {
+						t.Errorf("Open() error = %v, want it to mention %s", err, u)
+					}
+				}
+
+				return
+			}
+
+			if err != nil || got != data {
+				t.Fatalf("Open() = %q, %v, want %q", got, err, data)
+			}
+
+			if tt.main == http.StatusOK && n.Load() != 0 {
+				t.Errorf("mirror requested %d times, want 0", n.Load())
+			}
+		})
+	}
+}
+
+func TestOpenTruncated(t *testing.T) {
+	var n atomic.Int32
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if n.Add(1) == 1 {
+			// announces more than it sends.
+			w.Header().Set("Content-Length", "1000")
+			io.WriteString(w, data[:4])
+			return
+		}
+		io.WriteString(w, data)
+	}))
+	defer s.Close()
+
+	got, err := read(downloader(), Source{Code: "en", URL: s.URL})
+	if err != nil || got != data {
+		t.Fatalf("Open() = %q, %v, want %q", got, err, data)
+	}
+
+	if n.Load() != 2 {
+		t.Errorf("requests = %d, want 2", n.Load())
+	}
+}
+
+func TestOpenProgress(t *testing.T) {
+	s, _ := server(t, data, 200)
+
+	var done, total int64
+
+	d := downloader()
+	d.OnProgress = func(url string, n, t int64) { done, total = n, t }
+
+	if _, err := read(d, Source{Code: "en", URL: s.URL}); err != nil {
+		t.Fatal(err)
+	}
+
+	if done != int64(len(data)) || total != int64(len(data)) {
+		t.Errorf("progress = %d/%d, want %d/%d", done, total, len(data), len(data))
+	}
+}
+
+func TestOpenLocal(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "quran.json")
+	if err := os.WriteFile(file, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		location string
+		sum      string
+		err      error
+	}{
+		{"path", file, sum, nil},
+		{"file url", "file://" + file, "", nil},
+		{"mismatch", file, strings.Repeat("0", 64), ErrChecksum},
+		{"missing", file + ".missing", "", os.ErrNotExist},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := read(downloader(), Source{Code: "en", URL: tt.location, SHA256: tt.sum})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Open() error = %v, want %v", err, tt.err)
+			}
+
+			if tt.err == nil && got != data {
+				t.Errorf("Open() = %q, want %q", got, data)
+			}
+		})
+	}
+}
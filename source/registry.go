@@ -0,0 +1,142 @@
+package source
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// quranJSON are the locations of the quran-json data, the first one being
+// the main one and the others its mirrors, formatted with the suffix of the
+// language, empty for the arabic text.
+var quranJSON = []string{
+	"https://cdn.jsdelivr.net/npm/quran-json@3.1.2/dist/quran%s.json",
+	"https://unpkg.com/quran-json@3.1.2/dist/quran%s.json",
+}
+
+// builtin are the built-in sources, those of the quran-json data,
+// whose hashes are pinned in sums by go generate ./dataset.
+var builtin = []Source{
+	{Code: "ar", Name: "Arabic", Direction: RTL},
+	{Code: "bn", Name: "Bengali", Translator: "Muhiuddin Khan"},
+	{Code: "zh", Name: "Chinese", Translator: "Muhammad Makin"},
+	{Code: "en", Name: "English", Translator: "Saheeh International"},
+	{Code: "es", Name: "Spanish", Translator: "Muhammad Isa García"},
+	{Code: "fr", Name: "French", Translator: "Muhammad Hamidullah"},
+	{Code: "id", Name: "Indonesian", Translator: "Indonesian Islamic Affairs Ministry"},
+	{Code: "ru", Name: "Russian", Translator: "Elmir Kuliev"},
+	{Code: "sv", Name: "Swedish", Translator: "Knut Bernström"},
+	{Code: "tr", Name: "Turkish", Translator: "Diyanet İşleri"},
+	{Code: "ur", Name: "Urdu", Translator: "Abul A'la Maududi", Direction: RTL},
+	{Code: "transliteration", Name: "Transliteration"},
+}
+
+// Registry holds the sources of the languages.
+type Registry struct {
+	sources map[string]Source
+}
+
+// Builtin returns a registry of the built-in sources.
+func Builtin() *Registry {
+	r := &Registry{sources: make(map[string]Source, len(builtin))}
+
+	for _, s := range builtin {
+		suffix := ""
+		if s.Code != "ar" {
+			suffix = "_" + s.Code
+		}
+
+		s.URL = fmt.Sprintf(quranJSON[0], suffix)
+
+		for _, mirror := range quranJSON[1:] {
+			s.Mirrors = append(s.Mirrors, fmt.Sprintf(mirror, suffix))
+		}
+
+		s.SHA256 = sums[s.Code]
+
+		if err := r.Add(s); err != nil {
+			panic(err)
+		}
+	}
+
+	return r
+}
+
+// Load returns a registry of the built-in sources and of the sources of the
+// JSON file, an array of sources, if it exists. The sources of the file
+// replace the built-in ones of the same language, and their relative local
+// paths are resolved from the directory of the file.
+func Load(file string) (*Registry, error) {
+	r := Builtin()
+
+	data, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return r, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var sources []Source
+	if err = json.Unmarshal(data, &sources); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	// resolve resolves a relative local path from the directory of the file.
+	resolve := func(location string) string {
+		if location == "" || remote(location) || filepath.IsAbs(location) {
+			return location
+		}
+		return filepath.Join(filepath.Dir(file), location)
+	}
+
+	for _, s := range sources {
+		s.URL = resolve(s.URL)
+
+		for i, mirror := range s.Mirrors {
+			s.Mirrors[i] = resolve(mirror)
+		}
+
+		if err = r.Add(s); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+	}
+
+	return r, nil
+}
+
+// Add adds the source s to the registry, replacing
+// the existing source of the same language if any.
+func (r *Registry) Add(s Source) error {
+	if err := s.validate(); err != nil {
+		return err
+	}
+
+	r.sources[s.Code] = s
+
+	return nil
+}
+
+// Get returns the source of the language code.
+func (r *Registry) Get(code string) (s Source, ok bool) {
+	s, ok = r.sources[code]
+	return
+}
+
+// Sources returns the sources of the registry, sorted by language code.
+func (r *Registry) Sources() []Source {
+	sources := make([]Source, 0, len(r.sources))
+
+	for _, s := range r.sources {
+		sources = append(sources, s)
+	}
+
+	sort.Slice(sources, func(i, j int) bool {
+		return sources[i].Code < sources[j].Code
+	})
+
+	return sources
+}
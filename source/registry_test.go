@@ -0,0 +1,22 @@
+package source
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestBuiltinPinned(t *testing.T) {
+	if len(sums) == 0 {
+		t.Skip("the hashes are not generated, run go generate ./dataset")
+	}
+
+	for _, s := range Builtin().Sources() {
+		if b, err := hex.DecodeString(s.SHA256); err != nil || len(b) != 32 {
+			t.Errorf("source %s: SHA256 = %q, want the SHA-256 hash of its data", s.Code, s.SHA256)
+		}
+	}
+
+	if len(sums) != len(builtin) {
+		t.Errorf("len(sums) = %d, want one hash for each of the %d built-in sources", len(sums), len(builtin))
+	}
+}
@@ -0,0 +1,111 @@
+// Package source holds the registry of the sources of the
+// data of the languages, built-in or defined by the user.
+package source
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Format is the format of the data of a source.
+type Format string
+
+// enum of formats.
+const (
+	// QuranJSON is the format of the quran-json data,
+	// holding the arabic text and its translation.
+	QuranJSON Format = "quran-json"
+	// TanzilText is the Tanzil text format of translations (sura|aya|text).
+	TanzilText Format = "tanzil-text"
+	// TanzilXML is the Tanzil XML format of translations.
+	TanzilXML Format = "tanzil-xml"
+)
+
+// Direction is the writing direction of a language.
+type Direction string
+
+// enum of directions.
+const (
+	LTR Direction = "ltr"
+	RTL Direction = "rtl"
+)
+
+// Source is the source of the data of a language.
+type Source struct {
+	// Code is the code of the language the data is stored under.
+	Code string `json:"code"`
+	// Name is the display name of the language.
+	Name       string `json:"name"`
+	Translator string `json:"translator,omitempty"`
+	// URL is the location of the data, a http(s) url or a local path.
+	URL string `json:"url"`
+	// Mirrors are the locations of copies of the data, tried in order if URL fails.
+	Mirrors []string `json:"mirrors,omitempty"`
+	// SHA256 is the pinned hash of the data, in hexadecimal, checked if not empty.
+	SHA256    string    `json:"sha256,omitempty"`
+	Format    Format    `json:"format,omitempty"`
+	Direction Direction `json:"direction,omitempty"`
+}
+
+// validCode matches the valid language codes.
+var validCode = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// ValidCode returns true if code is a valid language code.
+func ValidCode(code string) bool {
+	return validCode.MatchString(code)
+}
+
+// Remote returns true if the data of the source is downloaded.
+func (s Source) Remote() bool {
+	return remote(s.URL)
+}
+
+// remote returns true if location is a http(s) url.
+func remote(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
+
+// validate checks the source, setting the default format and direction.
+func (s *Source) validate() error {
+	if !ValidCode(s.Code) {
+		return fmt.Errorf("invalid language code: %q", s.Code)
+	}
+
+	if s.URL == "" {
+		return fmt.Errorf("language %s: missing url", s.Code)
+	}
+
+	s.SHA256 = strings.ToLower(s.SHA256)
+	if _, err := hex.DecodeString(s.SHA256); err != nil || (s.SHA256 != "" && len(s.SHA256) != 2*sha256.Size) {
+		return fmt.Errorf("language %s: invalid sha256: %q", s.Code, s.SHA256)
+	}
+
+	if s.Name == "" {
+		s.Name = s.Code
+	}
+
+	switch s.Format {
+	case "":
+		s.Format = QuranJSON
+	case QuranJSON, TanzilText, TanzilXML:
+	default:
+		return fmt.Errorf("language %s: unsupported format: %q", s.Code, s.Format)
+	}
+
+	if s.Code == "ar" && s.Format != QuranJSON {
+		return fmt.Errorf("language %s: the arabic text must be in the %s format", s.Code, QuranJSON)
+	}
+
+	switch s.Direction {
+	case "":
+		s.Direction = LTR
+	case LTR, RTL:
+	default:
+		return fmt.Errorf("language %s: unsupported direction: %q", s.Code, s.Direction)
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/vanillaiice/quran-cli/db"
+)
+
+// InterlinearLines lays out words as read in arabic, from right to left,
+// each of them above its transliteration, gloss and root, wrapping at width
+// columns. The transliterations and roots are only shown if some words have one.
+func InterlinearLines(words []db.Word, width int) (lines []string) {
+	var transliterated, rooted bool
+	for _, w := range words {
+		transliterated = transliterated || w.Transliteration != ""
+		rooted = rooted || w.Root != ""
+	}
+
+	// cell returns the texts displayed in the column of a word.
+	cell := func(w db.Word) []string {
+		texts := []string{w.Text}
+		if transliterated {
+			texts = append(texts, w.Transliteration)
+		}
+		texts = append(texts, w.Gloss)
+		if rooted {
+			texts = append(texts, w.Root.String())
+		}
+		return texts
+	}
+
+	// flush appends a row of words, the first one on the right.
+	flush := func(row []db.Word, widths []int, used int) {
+		rowLines := make([]string, len(cell(db.Word{})))
+
+		for i := len(row) - 1; i >= 0; i-- {
+			for j, text := range cell(row[i]) {
+				pad := widths[i] - runewidth.StringWidth(text)
+				rowLines[j] += strings.Repeat(" ", pad/2) + text + strings.Repeat(" ", pad-pad/2)
+			}
+		}
+
+		for _, l := range rowLines {
+			lines = append(lines, strings.Repeat(" ", max(width-used, 0))+strings.TrimRight(l, " "))
+		}
+	}
+
+	var row []db.Word
+	var widths []int
+	var used int
+
+	for _, w := range words {
+		cw := 0
+		for _, text := range cell(w) {
+			cw = max(cw, runewidth.StringWidth(text))
+		}
+		cw += 2
+
+		if len(row) > 0 && used+cw > width {
+			flush(row, widths, used)
+			row, widths, used = nil, nil, 0
+		}
+
+		row = append(row, w)
+		widths = append(widths, cw)
+		used += cw
+	}
+
+	if len(row) > 0 {
+		flush(row, widths, used)
+	}
+
+	return
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/mattn/go-runewidth"
 	"github.com/muesli/reflow/wordwrap"
 	"github.com/vanillaiice/quran-cli/arabic"
 	"github.com/vanillaiice/quran-cli/db"
@@ -12,14 +13,8 @@ import (
 )
 
 // Run runs the application.
-func Run(s *db.Surah, l ...tui.Lang) (err error) {
-	var lang tui.Lang
-
-	if len(l) > 0 {
-		lang = l[0]
-	} else {
-		lang = tui.Both
-	}
+func Run(surahs []*db.Surah, cfg tui.Config) (err error) {
+	lang := cfg.Lang
 
 	t, err := newTerminal()
 	if err != nil {
@@ -42,8 +37,23 @@ func Run(s *db.Surah, l ...tui.Lang) (err error) {
 		t.RemoveTitle()
 	}()
 
+	lines := tui.Flatten(surahs)
+	if len(lines) == 0 {
+		return tui.ErrNoVerses
+	}
+
 	var currentLine, topLine int
 
+	if cfg.Start > 0 && cfg.Start < len(lines) {
+		currentLine, topLine = cfg.Start, cfg.Start
+	}
+
+	// message is displayed in the status line until the next key press.
+	var message string
+
+	// showNotes is true if the notes are displayed under their verses.
+	var showNotes bool
+
 	w, h := t.Size()
 
 	printLines := func() {
@@ -53,27 +63,58 @@ func Run(s *db.Surah, l ...tui.Lang) (err error) {
 
 		var linesPrinted int
 
-		for i := topLine; i < s.TotalVerses && linesPrinted < h-2; i++ {
-			v := s.Verses[i]
+		for i := topLine; i < len(lines) && linesPrinted < h-2; i++ {
+			v := lines[i].Verse
+
+			if len(surahs) > 1 && (i == 0 || lines[i-1].Surah != lines[i].Surah) {
+				t.Bold()
+				t.WriteString(tui.Header(lines[i].Surah) + "\n")
+				t.Reset()
+				linesPrinted++
+			}
+
+			m := cfg.Marker(lines[i].Surah.Id, v.Id)
 
 			var s string
 
+			// interlinear are the lines of the interlinear layout of the
+			// verse, not wrapped since they already fit the screen.
+			var interlinear []string
+
 			switch lang {
 			case tui.Arabic:
-				s = fmt.Sprintf("%s. %s", arabic.ToArabic(v.Id), v.Text)
+				s = fmt.Sprintf("%s.%s %s", arabic.ToArabic(v.Id), m, v.Text)
 			case tui.Translation:
-				s = fmt.Sprintf("%d. %s", v.Id, v.Translation)
+				s = fmt.Sprintf("%d.%s %s", v.Id, m, v.Translation)
+			case tui.Interlinear:
+				if len(v.Words) > 0 {
+					s = fmt.Sprintf("%s.%s", arabic.ToArabic(v.Id), m)
+					interlinear = tui.InterlinearLines(v.Words, w-2)
+				} else {
+					s = fmt.Sprintf("%s.%s %s", arabic.ToArabic(v.Id), m, v.Text)
+				}
 			case tui.Both:
 				fallthrough
 			default:
 				if v.Translation != "" {
-					s = fmt.Sprintf("%d. %s\n%s. %s", v.Id, v.Translation, arabic.ToArabic(v.Id), v.Text)
+					s = fmt.Sprintf("%d.%s %s\n%s. %s", v.Id, m, v.Translation, arabic.ToArabic(v.Id), v.Text)
 				} else {
-					s = fmt.Sprintf("%s. %s", arabic.ToArabic(v.Id), v.Text)
+					s = fmt.Sprintf("%s.%s %s", arabic.ToArabic(v.Id), m, v.Text)
 				}
 			}
 
 			wrapped := strings.Split(wordwrap.String(s, w-1), "\n")
+			wrapped = append(wrapped, interlinear...)
+
+			// translations written right to left are aligned right,
+			// leaving room for the marker of the current line.
+			if lang == tui.Translation && cfg.RTL {
+				alignRight(wrapped, w-2)
+			}
+
+			if note, ok := cfg.Notes[tui.VerseKey{Surah: lines[i].Surah.Id, Verse: v.Id}]; ok && showNotes {
+				wrapped = append(wrapped, strings.Split(wordwrap.String(tui.NoteMarker+" "+note, w-1), "\n")...)
+			}
 
 			for j := 0; j < len(wrapped) && linesPrinted < h-2; j++ {
 				wr := wrapped[j]
@@ -95,15 +136,22 @@ func Run(s *db.Surah, l ...tui.Lang) (err error) {
 			linesPrinted++
 		}
 
-		t.WriteStringRepeat("~\n", h-linesPrinted-1)
+		t.WriteStringRepeat("~\n", max(h-linesPrinted-1, 0))
+
+		s, v := lines[currentLine].Surah, lines[currentLine].Verse
 
 		t.Reverse()
 		t.Bold()
 		t.WriteStringRepeat(" ", w)
-		b := []byte(fmt.Sprintf(" Verse %d/%d ", currentLine+1, s.TotalVerses))
+		b := []byte(fmt.Sprintf(" Juz %d | Page %d | Verse %d/%d ", db.Juz.Of(s.Id, v.Id), db.Page.Of(s.Id, v.Id), v.Id, s.TotalVerses))
 		t.WriteStringRepeat("\b", len(b)-1)
 		t.Write(b)
-		t.WriteString(fmt.Sprintf("\r #%d %s (%s) - %s (%s) ", s.Id, s.Name, s.Transliteration, s.Translation, s.Type))
+		if message != "" {
+			t.WriteString(fmt.Sprintf("\r %s ", message))
+			message = ""
+		} else {
+			t.WriteString(fmt.Sprintf("\r %s ", tui.Header(s)))
+		}
 		// t.WriteString(" | ↑/k up • ↓/j down • q/esc exit • g/G top/bottom ")
 
 		t.Reset()
@@ -125,7 +173,7 @@ func Run(s *db.Surah, l ...tui.Lang) (err error) {
 		}
 
 		down := func() {
-			if currentLine < s.TotalVerses-1 {
+			if currentLine < len(lines)-1 {
 				currentLine++
 				if currentLine >= topLine+2 {
 					topLine++
@@ -155,9 +203,47 @@ func Run(s *db.Surah, l ...tui.Lang) (err error) {
 					topLine = 0
 					printLines()
 				case 'G':
-					currentLine = s.TotalVerses - 1
-					topLine = s.TotalVerses - 2
+					currentLine = len(lines) - 1
+					topLine = max(len(lines)-2, 0)
+					printLines()
+				case 'b':
+					if cfg.Bookmark != nil {
+						l := lines[currentLine]
+						msg, err := cfg.Bookmark(l.Surah.Id, l.Verse.Id)
+						if err != nil {
+							msg = err.Error()
+						}
+						message = msg
+						printLines()
+					}
+				case 'n':
+					showNotes = !showNotes
 					printLines()
+				case 'e':
+					if cfg.EditNote != nil {
+						l := lines[currentLine]
+
+						var (
+							text    string
+							editErr error
+						)
+
+						if err := t.Suspend(func() {
+							text, editErr = cfg.EditNote(l.Surah.Id, l.Verse.Id)
+						}); err != nil {
+							done <- err
+							return
+						}
+
+						if editErr != nil {
+							message = editErr.Error()
+						} else {
+							cfg.SetNote(l.Surah.Id, l.Verse.Id, text)
+							showNotes = true
+						}
+
+						printLines()
+					}
 				case 'q', 27:
 					done <- nil
 				}
@@ -174,5 +260,21 @@ func Run(s *db.Surah, l ...tui.Lang) (err error) {
 		}
 	}()
 
-	return <-done
+	if err = <-done; err != nil {
+		return
+	}
+
+	if cfg.Quit != nil {
+		l := lines[currentLine]
+		err = cfg.Quit(l.Surah.Id, l.Verse.Id)
+	}
+
+	return
+}
+
+// alignRight pads the lines with spaces on the left, so they end at column width.
+func alignRight(lines []string, width int) {
+	for i, l := range lines {
+		lines[i] = strings.Repeat(" ", max(width-runewidth.StringWidth(l), 0)) + l
+	}
 }
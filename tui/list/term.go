@@ -64,6 +64,27 @@ func (t *terminal) Restore() error {
 	return term.Restore(t.fd, t.state)
 }
 
+// Suspend restores the initial terminal state while f runs.
+func (t *terminal) Suspend(f func()) (err error) {
+	t.ShowCursor()
+	t.ExitAltScreen()
+
+	if err = term.Restore(t.fd, t.state); err != nil {
+		return
+	}
+
+	f()
+
+	if t.state, err = term.MakeRaw(t.fd); err != nil {
+		return
+	}
+
+	t.AltScreen()
+	t.HideCursor()
+
+	return
+}
+
 // Size returns the current size of the terminal.
 func (t *terminal) Size() (int, int) {
 	w, h, err := term.GetSize(t.fd)
@@ -1,5 +1,15 @@
 package tui
 
+import (
+	"errors"
+	"fmt"
+
+	"github.com/vanillaiice/quran-cli/db"
+)
+
+// ErrNoVerses is returned when there are no verses to display.
+var ErrNoVerses = errors.New("no verses to display")
+
 // Lang is a type for languages.
 type Lang int
 
@@ -8,4 +18,105 @@ const (
 	Arabic Lang = iota
 	Translation
 	Both
+	// Interlinear displays each arabic word above its translation.
+	Interlinear
 )
+
+// String returns the name of the language.
+func (l Lang) String() string {
+	switch l {
+	case Arabic:
+		return "arabic"
+	case Translation:
+		return "translation"
+	case Interlinear:
+		return "interlinear"
+	default:
+		return "both"
+	}
+}
+
+// NoteMarker marks the verses having a note.
+const NoteMarker = "✎"
+
+// VerseKey identifies a verse.
+type VerseKey struct {
+	Surah int
+	Verse int
+}
+
+// Config is the configuration of a reading session.
+type Config struct {
+	// Lang is the languages to display.
+	Lang Lang
+	// Start is the index of the verse to start reading at.
+	Start int
+	// RTL is true if the translation is written right to left.
+	RTL bool
+	// Bookmark bookmarks a verse, returning a message to display.
+	Bookmark func(surahId, verseId int) (string, error)
+	// Quit is called with the selected verse when the session ends.
+	Quit func(surahId, verseId int) error
+	// Notes are the notes of the verses.
+	Notes map[VerseKey]string
+	// EditNote edits the note of a verse, returning its new text.
+	EditNote func(surahId, verseId int) (string, error)
+	// Tafsir returns the commentary on a verse, empty if there is none.
+	Tafsir func(surahId, verseId int) (string, error)
+}
+
+// Marker returns the markers to display next to a verse,
+// prefixed with a space, or an empty string if there are none.
+func (c *Config) Marker(surahId, verseId int) (m string) {
+	if db.IsSajdah(surahId, verseId) {
+		m += " " + db.SajdahMarker
+	}
+	if _, ok := c.Notes[VerseKey{surahId, verseId}]; ok {
+		m += " " + NoteMarker
+	}
+	return
+}
+
+// SetNote updates the note of a verse after it was edited.
+func (c *Config) SetNote(surahId, verseId int, text string) {
+	if c.Notes == nil {
+		c.Notes = make(map[VerseKey]string)
+	}
+
+	if text == "" {
+		delete(c.Notes, VerseKey{surahId, verseId})
+	} else {
+		c.Notes[VerseKey{surahId, verseId}] = text
+	}
+}
+
+// Line is a verse to display, along with its surah.
+type Line struct {
+	Surah *db.Surah
+	Verse db.Verse
+}
+
+// Flatten returns the verses of the surahs, in order.
+func Flatten(surahs []*db.Surah) (lines []Line) {
+	for _, s := range surahs {
+		for _, v := range s.Verses {
+			lines = append(lines, Line{Surah: s, Verse: v})
+		}
+	}
+	return
+}
+
+// Header returns the description of a surah.
+func Header(s *db.Surah) string {
+	return fmt.Sprintf("#%d %s (%s) - %s (%s)", s.Id, s.Name, s.Transliteration, s.Translation, s.Type)
+}
+
+// Index returns the index of a verse in the surahs, or 0 if not found.
+func Index(surahs []*db.Surah, surahId, verseId int) int {
+	for i, l := range Flatten(surahs) {
+		if l.Surah.Id == surahId && l.Verse.Id == verseId {
+			return i
+		}
+	}
+	return 0
+}
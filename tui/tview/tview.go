@@ -12,17 +12,14 @@ import (
 )
 
 // Run runs the tview application.
-func Run(surah *db.Surah, l ...tui.Lang) (err error) {
-	var lang tui.Lang
+func Run(surahs []*db.Surah, cfg tui.Config) (err error) {
+	lang := cfg.Lang
 
-	if len(l) > 0 {
-		lang = l[0]
-	} else {
-		lang = tui.Both
+	lines := tui.Flatten(surahs)
+	if len(lines) == 0 {
+		return tui.ErrNoVerses
 	}
 
-	_ = lang
-
 	app := tview.NewApplication()
 
 	textView := tview.NewTextView().
@@ -35,28 +32,62 @@ func Run(surah *db.Surah, l ...tui.Lang) (err error) {
 
 	textView.SetTitle(" The Holy Quran ")
 
+	// showNotes is true if the notes are displayed under their verses.
+	var showNotes bool
+
+	// width is the width of the text view, used to lay out interlinear
+	// verses, until it is known after the first draw.
+	width := 80
+
 	var i int
 	drawFunc := func() {
 		var s string
 
-		for _, v := range surah.Verses {
+		i = 0
+		textView.Clear()
+
+		for j, line := range lines {
+			v := line.Verse
+			m := cfg.Marker(line.Surah.Id, v.Id)
+
+			if len(surahs) > 1 && (j == 0 || lines[j-1].Surah != line.Surah) {
+				s += fmt.Sprintf("[::b]%s[::-]\n\n", replaceBrackets(tui.Header(line.Surah)))
+			}
+
+			note, hasNote := cfg.Notes[tui.VerseKey{Surah: line.Surah.Id, Verse: v.Id}]
+			if hasNote && showNotes {
+				note = fmt.Sprintf("[::d]%s %s[::-]\n", tui.NoteMarker, replaceBrackets(note))
+			} else {
+				note = ""
+			}
+
 			switch lang {
 			case tui.Arabic:
 				textView.SetTextAlign(tview.AlignRight)
 				v.Text = replaceBrackets(v.Text)
-				s += fmt.Sprintf(`["%d"]%s. %s[""]`+"\n", i, arabic.ToArabic(v.Id), v.Text)
+				s += fmt.Sprintf(`["%d"]%s.%s %s`+"\n%s"+`[""]`, i, arabic.ToArabic(v.Id), m, v.Text, note)
 				i++
 			case tui.Translation:
+				if cfg.RTL {
+					textView.SetTextAlign(tview.AlignRight)
+				}
 				v.Translation = replaceBrackets(v.Translation)
-				s += fmt.Sprintf(`["%d"]%d. %s[""]`+"\n", i, v.Id, v.Translation)
+				s += fmt.Sprintf(`["%d"]%d.%s %s`+"\n%s"+`[""]`, i, v.Id, m, v.Translation, note)
+				i++
+			case tui.Interlinear:
+				text := replaceBrackets(v.Text)
+				if len(v.Words) > 0 {
+					text = "\n" + replaceBrackets(strings.Join(tui.InterlinearLines(v.Words, width), "\n"))
+				}
+				s += fmt.Sprintf(`["%d"]%s.%s %s`+"\n%s"+`[""]`, i, arabic.ToArabic(v.Id), m, text, note)
 				i++
 			case tui.Both:
 				fallthrough
 			default:
 				v.Text = replaceBrackets(v.Text)
 				v.Translation = replaceBrackets(v.Translation)
-				s += fmt.Sprintf(`["%d"]%d. %s`+"\n", i, v.Id, v.Translation)
-				s += fmt.Sprintf(`%s. %s[""]`+"\n", arabic.ToArabic(v.Id), v.Text)
+				s += fmt.Sprintf(`["%d"]%d.%s %s`+"\n", i, v.Id, m, v.Translation)
+				s += fmt.Sprintf(`%s. %s`+"\n%s"+`[""]`, arabic.ToArabic(v.Id), v.Text, note)
 				i++
 			}
 
@@ -69,12 +100,76 @@ func Run(surah *db.Surah, l ...tui.Lang) (err error) {
 	drawFunc()
 
 	helpText := " ↑/k up • ↓/j down • q/esc exit • g/G top/bottom"
+	if cfg.Bookmark != nil {
+		helpText += " • b bookmark"
+	}
+	if cfg.EditNote != nil {
+		helpText += " • n/e show/edit notes"
+	}
+	if cfg.Tafsir != nil {
+		helpText += " • t tafsir"
+	}
 
 	var sel int
 
-	frame := tview.NewFrame(textView).
-		AddText(helpText, false, tview.AlignLeft, tcell.ColorWhite).
-		AddText(fmt.Sprintf(" #%d %s (%s) - %s (%s) | verse %d/%d", surah.Id, surah.Name, surah.Transliteration, surah.Translation, surah.Type, sel+1, surah.TotalVerses), false, tview.AlignLeft, tcell.ColorWhite)
+	if cfg.Start > 0 && cfg.Start < len(lines) {
+		sel = cfg.Start
+	}
+
+	// message is displayed instead of the help text until the next key press.
+	var message string
+
+	status := func() string {
+		surah, v := lines[sel].Surah, lines[sel].Verse
+		return fmt.Sprintf(" %s | juz %d | page %d | verse %d/%d", tui.Header(surah), db.Juz.Of(surah.Id, v.Id), db.Page.Of(surah.Id, v.Id), v.Id, surah.TotalVerses)
+	}
+
+	// panel shows the commentary on the selected verse next to the text.
+	panel := tview.NewTextView().SetWordWrap(true)
+	panel.SetBorder(true).SetBorderAttributes(tcell.AttrDim)
+
+	layout := tview.NewFlex().AddItem(textView, 0, 1, true)
+
+	// showTafsir is true if the commentary panel is displayed,
+	// and shown is the verse whose commentary it displays.
+	var (
+		showTafsir bool
+		shown      tui.VerseKey
+	)
+
+	updateTafsir := func() {
+		l := lines[sel]
+		key := tui.VerseKey{Surah: l.Surah.Id, Verse: l.Verse.Id}
+		if !showTafsir || key == shown {
+			return
+		}
+		shown = key
+
+		text, err := cfg.Tafsir(key.Surah, key.Verse)
+		if err != nil {
+			text = err.Error()
+		} else if text == "" {
+			text = "no commentary on this verse"
+		}
+
+		panel.SetTitle(fmt.Sprintf(" Tafsir %d:%d ", key.Surah, key.Verse))
+		panel.SetText(text).ScrollToBeginning()
+	}
+
+	frame := tview.NewFrame(layout)
+
+	drawFrame := func() {
+		text := helpText
+		if message != "" {
+			text, message = " "+message, ""
+		}
+
+		frame.Clear().
+			AddText(text, false, tview.AlignLeft, tcell.ColorWhite).
+			AddText(status(), false, tview.AlignLeft, tcell.ColorWhite)
+	}
+
+	drawFrame()
 
 	up := func() {
 		if sel > 0 {
@@ -100,6 +195,49 @@ func Run(surah *db.Surah, l ...tui.Lang) (err error) {
 				sel = 0
 			case 'G':
 				sel = i - 1
+			case 'b':
+				if cfg.Bookmark != nil {
+					l := lines[sel]
+					msg, err := cfg.Bookmark(l.Surah.Id, l.Verse.Id)
+					if err != nil {
+						msg = err.Error()
+					}
+					message = msg
+				}
+			case 'n':
+				showNotes = !showNotes
+				drawFunc()
+			case 'e':
+				if cfg.EditNote != nil {
+					l := lines[sel]
+
+					var (
+						text    string
+						editErr error
+					)
+
+					app.Suspend(func() {
+						text, editErr = cfg.EditNote(l.Surah.Id, l.Verse.Id)
+					})
+
+					if editErr != nil {
+						message = editErr.Error()
+					} else {
+						cfg.SetNote(l.Surah.Id, l.Verse.Id, text)
+						showNotes = true
+						drawFunc()
+					}
+				}
+			case 't':
+				if cfg.Tafsir != nil {
+					showTafsir = !showTafsir
+					if showTafsir {
+						shown = tui.VerseKey{}
+						layout.AddItem(panel, 0, 1, false)
+					} else {
+						layout.RemoveItem(panel)
+					}
+				}
 			case 'q':
 				app.Stop()
 			}
@@ -109,11 +247,24 @@ func Run(surah *db.Surah, l ...tui.Lang) (err error) {
 			up()
 		case tcell.KeyDown:
 			down()
+		case tcell.KeyPgUp, tcell.KeyPgDn:
+			// scrolls the commentary instead of the text while it is displayed.
+			if showTafsir {
+				_, _, _, h := panel.GetInnerRect()
+				row, _ := panel.GetScrollOffset()
+				if event.Key() == tcell.KeyPgUp {
+					row = max(row-h, 0)
+				} else {
+					row += h
+				}
+				panel.ScrollTo(row, 0)
+				event = nil
+			}
 		}
 
-		frame.Clear().
-			AddText(helpText, false, tview.AlignLeft, tcell.ColorWhite).
-			AddText(fmt.Sprintf(" #%d %s - %s (%s) | verse %d/%d", surah.Id, surah.Name, surah.Transliteration, surah.Type, sel+1, surah.TotalVerses), false, tview.AlignLeft, tcell.ColorWhite)
+		drawFrame()
+
+		updateTafsir()
 
 		textView.Highlight(fmt.Sprint(sel))
 
@@ -122,11 +273,36 @@ func Run(surah *db.Surah, l ...tui.Lang) (err error) {
 		return event
 	})
 
-	textView.Highlight("0")
+	textView.Highlight(fmt.Sprint(sel))
+	textView.ScrollToHighlight()
 
 	textView.SetBorder(true).SetBorderAttributes(tcell.AttrDim)
 
-	return app.SetRoot(frame, true).SetFocus(frame).Run()
+	if lang == tui.Interlinear {
+		// lays out the verses again when the width of the text view changes.
+		textView.SetDrawFunc(func(screen tcell.Screen, x, y, w, h int) (int, int, int, int) {
+			if w-2 != width && w > 2 {
+				width = w - 2
+				go app.QueueUpdateDraw(func() {
+					drawFunc()
+					textView.Highlight(fmt.Sprint(sel))
+					textView.ScrollToHighlight()
+				})
+			}
+			return x + 1, y + 1, w - 2, h - 2
+		})
+	}
+
+	if err = app.SetRoot(frame, true).SetFocus(frame).Run(); err != nil {
+		return
+	}
+
+	if cfg.Quit != nil {
+		l := lines[sel]
+		err = cfg.Quit(l.Surah.Id, l.Verse.Id)
+	}
+
+	return
 }
 
 // replaceBrackets replaces brackets with parentheses in a string.
@@ -0,0 +1,96 @@
+package user
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Bookmark is a named verse.
+type Bookmark struct {
+	Name      string
+	SurahId   int
+	VerseId   int
+	CreatedAt time.Time
+}
+
+// AddBookmark bookmarks a verse under the name name,
+// replacing the bookmark with the same name if any.
+func (c *Conn) AddBookmark(name string, surahId, verseId int) error {
+	_, err := c.db.Exec(`
+		INSERT INTO Bookmarks VALUES (?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+			surah_id = excluded.surah_id,
+			verse_id = excluded.verse_id,
+			created_at = excluded.created_at`,
+		name, surahId, verseId, time.Now().Unix(),
+	)
+	return err
+}
+
+// GetBookmark returns the bookmark with the name name.
+func (c *Conn) GetBookmark(name string) (*Bookmark, error) {
+	var (
+		b       Bookmark
+		created int64
+	)
+
+	err := c.db.QueryRow(`SELECT name, surah_id, verse_id, created_at FROM Bookmarks WHERE name = ?`, name).
+		Scan(&b.Name, &b.SurahId, &b.VerseId, &created)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	b.CreatedAt = time.Unix(created, 0)
+
+	return &b, nil
+}
+
+// GetBookmarks returns all the bookmarks, sorted by name.
+func (c *Conn) GetBookmarks() ([]*Bookmark, error) {
+	rows, err := c.db.Query(`SELECT name, surah_id, verse_id, created_at FROM Bookmarks ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bookmarks []*Bookmark
+
+	for rows.Next() {
+		var (
+			b       Bookmark
+			created int64
+		)
+
+		if err = rows.Scan(&b.Name, &b.SurahId, &b.VerseId, &created); err != nil {
+			return nil, err
+		}
+
+		b.CreatedAt = time.Unix(created, 0)
+
+		bookmarks = append(bookmarks, &b)
+	}
+
+	return bookmarks, rows.Err()
+}
+
+// RemoveBookmark deletes the bookmark with the name name.
+func (c *Conn) RemoveBookmark(name string) error {
+	res, err := c.db.Exec(`DELETE FROM Bookmarks WHERE name = ?`, name)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package user
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Note is a personal note about a verse.
+type Note struct {
+	SurahId   int
+	VerseId   int
+	Text      string
+	UpdatedAt time.Time
+}
+
+// SetNote sets the note of a verse, removing it if text is empty.
+func (c *Conn) SetNote(surahId, verseId int, text string) error {
+	if text == "" {
+		_, err := c.db.Exec(`DELETE FROM Notes WHERE surah_id = ? AND verse_id = ?`, surahId, verseId)
+		return err
+	}
+
+	_, err := c.db.Exec(`
+		INSERT INTO Notes VALUES (?, ?, ?, ?)
+		ON CONFLICT(surah_id, verse_id) DO UPDATE SET
+			text = excluded.text,
+			updated_at = excluded.updated_at`,
+		surahId, verseId, text, time.Now().Unix(),
+	)
+	return err
+}
+
+// GetNote returns the note of a verse.
+func (c *Conn) GetNote(surahId, verseId int) (*Note, error) {
+	var (
+		n       Note
+		updated int64
+	)
+
+	err := c.db.QueryRow(`SELECT surah_id, verse_id, text, updated_at FROM Notes WHERE surah_id = ? AND verse_id = ?`, surahId, verseId).
+		Scan(&n.SurahId, &n.VerseId, &n.Text, &updated)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	n.UpdatedAt = time.Unix(updated, 0)
+
+	return &n, nil
+}
+
+// GetNotes returns all the notes, sorted by verse.
+func (c *Conn) GetNotes() ([]*Note, error) {
+	rows, err := c.db.Query(`SELECT surah_id, verse_id, text, updated_at FROM Notes ORDER BY surah_id, verse_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notes []*Note
+
+	for rows.Next() {
+		var (
+			n       Note
+			updated int64
+		)
+
+		if err = rows.Scan(&n.SurahId, &n.VerseId, &n.Text, &updated); err != nil {
+			return nil, err
+		}
+
+		n.UpdatedAt = time.Unix(updated, 0)
+
+		notes = append(notes, &n)
+	}
+
+	return notes, rows.Err()
+}
+
+// RemoveNote deletes the note of a verse.
+func (c *Conn) RemoveNote(surahId, verseId int) error {
+	res, err := c.db.Exec(`DELETE FROM Notes WHERE surah_id = ? AND verse_id = ?`, surahId, verseId)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package user
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Position is the verse a reading session ended at,
+// along with the language and mode it was read in.
+type Position struct {
+	SurahId   int
+	VerseId   int
+	Lang      string
+	Mode      string
+	UpdatedAt time.Time
+}
+
+// SetLastRead saves the position of the last reading session.
+func (c *Conn) SetLastRead(p *Position) error {
+	_, err := c.db.Exec(`
+		INSERT INTO LastRead VALUES (1, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			surah_id = excluded.surah_id,
+			verse_id = excluded.verse_id,
+			lang = excluded.lang,
+			mode = excluded.mode,
+			updated_at = excluded.updated_at`,
+		p.SurahId, p.VerseId, p.Lang, p.Mode, time.Now().Unix(),
+	)
+	return err
+}
+
+// GetLastRead returns the position of the last reading session.
+func (c *Conn) GetLastRead() (*Position, error) {
+	var (
+		p       Position
+		updated int64
+	)
+
+	err := c.db.QueryRow(`SELECT surah_id, verse_id, lang, mode, updated_at FROM LastRead WHERE id = 1`).
+		Scan(&p.SurahId, &p.VerseId, &p.Lang, &p.Mode, &updated)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	p.UpdatedAt = time.Unix(updated, 0)
+
+	return &p, nil
+}
@@ -0,0 +1,80 @@
+package user
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/vanillaiice/quran-cli/migrate"
+	_ "modernc.org/sqlite"
+)
+
+// ErrNotFound is returned when the requested data does not exist.
+var ErrNotFound = errors.New("not found")
+
+// migrations are the migrations of the user data schema, in order.
+//
+// Migrations must never be changed once released,
+// schema changes are made by appending new ones.
+var migrations = []migrate.Migration{
+	// 1: bookmarks.
+	migrate.Exec(`
+	CREATE TABLE IF NOT EXISTS Bookmarks(
+		name TEXT PRIMARY KEY,
+		surah_id INTEGER NOT NULL,
+		verse_id INTEGER NOT NULL,
+		created_at INTEGER NOT NULL
+	);
+	`),
+	// 2: last read position.
+	migrate.Exec(`
+	CREATE TABLE IF NOT EXISTS LastRead(
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		surah_id INTEGER NOT NULL,
+		verse_id INTEGER NOT NULL,
+		lang TEXT NOT NULL,
+		mode TEXT NOT NULL,
+		updated_at INTEGER NOT NULL
+	);
+	`),
+	// 3: notes.
+	migrate.Exec(`
+	CREATE TABLE IF NOT EXISTS Notes(
+		surah_id INTEGER NOT NULL,
+		verse_id INTEGER NOT NULL,
+		text TEXT NOT NULL,
+		updated_at INTEGER NOT NULL,
+		PRIMARY KEY (surah_id, verse_id)
+	);
+	`),
+}
+
+// Conn is a connection to the user data database,
+// holding the data created while reading.
+type Conn struct {
+	db *sql.DB
+}
+
+// New opens the user data database at path, creating it if needed.
+func New(path string) (*Conn, error) {
+	conn, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = conn.Ping(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if err = migrate.Run(conn, migrations); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &Conn{db: conn}, nil
+}
+
+// Close closes the connection.
+func (c *Conn) Close() error {
+	return c.db.Close()
+}